@@ -4,29 +4,20 @@
 /*
 File: cmd/axis/main.go
 Description: Entry point for the Axis application. Initializes Google Workspace services
-using service account impersonation and starts the web-based terminal server. Updated
-to use read-only scopes matching Domain-Wide Delegation.
+using service account impersonation, checks the delegated scopes, and starts the
+web-based terminal server.
 */
 package main
 
 import (
 	"context"
 	"log"
-	"os"
 
+	"axis/internal/config"
 	"axis/internal/server"
 	"axis/internal/workspace"
 
 	"github.com/joho/godotenv"
-	admin "google.golang.org/api/admin/directory/v1"
-	chat "google.golang.org/api/chat/v1"
-	docs "google.golang.org/api/docs/v1"
-	drive "google.golang.org/api/drive/v3"
-	gmail "google.golang.org/api/gmail/v1"
-	"google.golang.org/api/impersonate"
-	keep "google.golang.org/api/keep/v1"
-	"google.golang.org/api/option"
-	sheets "google.golang.org/api/sheets/v4"
 )
 
 func main() {
@@ -37,109 +28,57 @@ func main() {
 
 	ctx := context.Background()
 
-	// 2. Validation
-	adminEmail := os.Getenv("ADMIN_EMAIL")
-	serviceAccountEmail := os.Getenv("SERVICE_ACCOUNT_EMAIL")
-	userEmail := os.Getenv("USER_EMAIL")
-
-	if adminEmail == "" || serviceAccountEmail == "" || userEmail == "" {
-		log.Fatal("Error: ADMIN_EMAIL, SERVICE_ACCOUNT_EMAIL, and USER_EMAIL must be set.")
-	}
-
-	log.Printf("Initializing Services for %s via SA %s...", adminEmail, serviceAccountEmail)
-
-	// 3. Create the Token Source with Admin and Keep scopes
-	// Changed AdminDirectoryUserScope to AdminDirectoryUserReadonlyScope to match DWD permissions
-	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-		TargetPrincipal: serviceAccountEmail,
-		Subject:         adminEmail,
-		Scopes: []string{
-			admin.AdminDirectoryUserReadonlyScope,
-			keep.KeepScope,
-			docs.DocumentsScope,
-			sheets.SpreadsheetsScope,
-			drive.DriveReadonlyScope,
-			gmail.GmailModifyScope,
-			"https://www.googleapis.com/auth/chat.spaces.create",
-		},
-	})
+	// 2. Configuration
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to create token source: %v", err)
+		log.Fatalf("Error: invalid configuration:\n%v", err)
 	}
 
-	// 3b. Create the Bot Token Source for Chat App (acting as the bot, not the user)
-	chatBotTs, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-		TargetPrincipal: serviceAccountEmail,
-		// No Subject field. This ensures we authenticate as the application itself.
-		Scopes: []string{
-			"https://www.googleapis.com/auth/chat.bot",
-			"https://www.googleapis.com/auth/chat.messages.create",
-			"https://www.googleapis.com/auth/chat.spaces.create",
-		},
+	log.Printf("Initializing Services for %s via SA %s...", cfg.AdminEmail, cfg.ServiceAccountEmail)
+
+	// 3. Build the delegated Workspace services. The pool caches one Service per
+	// impersonated subject so the acting user can be switched at runtime.
+	pool := workspace.NewServicePool(func(ctx context.Context, subject string) (*workspace.Service, error) {
+		svc, err := workspace.NewDelegatedService(ctx, cfg.ServiceAccountEmail, subject, cfg.Forms)
+		if err != nil {
+			return nil, err
+		}
+		svc.SetExclusions(cfg.Exclusions)
+		svc.SetSharedDrive(cfg.SharedDriveID)
+		svc.SetOwnedOnly(cfg.OwnedOnly)
+		return svc, nil
 	})
-	if err != nil {
-		log.Fatalf("Failed to create token source: %v", err)
-	}
 
-	// 4. Create the Google API Services
-	adminSvc, err := admin.NewService(ctx, option.WithTokenSource(ts))
+	ws, err := pool.Get(ctx, cfg.AdminEmail)
 	if err != nil {
-		log.Fatalf("Failed to create Admin service: %v", err)
+		log.Fatalf("Failed to initialize workspace services: %v", err)
 	}
 
-	keepSvc, err := keep.NewService(ctx, option.WithTokenSource(ts))
+	// 4. Verification check
+	user, err := ws.GetUser(cfg.UserEmail)
 	if err != nil {
-		log.Fatalf("Failed to create Keep service: %v", err)
-	}
-
-	docsSvc, err := docs.NewService(ctx, option.WithTokenSource(ts))
-	if err != nil {
-		log.Fatalf("Failed to create Docs service: %v", err)
-	}
-
-	sheetsSvc, err := sheets.NewService(ctx, option.WithTokenSource(ts))
-	if err != nil {
-		log.Fatalf("Failed to create Sheets service: %v", err)
-	}
-
-	driveSvc, err := drive.NewService(ctx, option.WithTokenSource(ts))
-	if err != nil {
-		log.Fatalf("Failed to create Drive service: %v", err)
-	}
-
-	gmailSvc, err := gmail.NewService(ctx, option.WithTokenSource(ts))
-	if err != nil {
-		log.Fatalf("Failed to create Gmail service: %v", err)
+		log.Fatalf("Verification failed: %v", err)
 	}
-
-	chatUserSvc, err := chat.NewService(ctx, option.WithTokenSource(ts))
-	if err != nil {
-		log.Fatalf("Failed to create Chat User service: %v", err)
+	log.Printf("Verification successful: %s (%s)", user.Name, user.Email)
+	if cfg.Forms && !ws.FormsEnabled() {
+		log.Printf("Warning: the delegation grant lacks %s; forms support is disabled.", workspace.FormsScope)
 	}
 
-	chatBotSvc, err := chat.NewService(ctx, option.WithTokenSource(chatBotTs))
-	if err != nil {
-		log.Fatalf("Failed to create Chat Bot service: %v", err)
+	// Fail fast if the delegation grant is missing a scope the registry needs,
+	// rather than surfacing 403s later from individual requests.
+	if err := ws.CheckScopes(ctx); err != nil {
+		log.Fatalf("Scope check failed:\n%v", err)
 	}
 
-	// 5. Initialize internal workspace wrapper
-	ws := workspace.NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, gmailSvc, chatUserSvc, chatBotSvc)
-
-	// 6. Verification check
-	user, err := ws.GetUser(userEmail)
+	// 5. Start the Persistent TUI Server
+	srv, err := server.NewServer(ws, user, cfg.Server)
 	if err != nil {
-		log.Fatalf("Verification failed: %v", err)
+		log.Fatalf("Server setup failed: %v", err)
 	}
-	log.Printf("Verification successful: %s (%s)", user.Name, user.Email)
-
-	// 7. Start the Persistent TUI Server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if cfg.Server.ImpersonationToken != "" {
+		srv.EnableImpersonation(pool, cfg.AdminEmail)
 	}
-
-	srv := server.NewServer(ws, user)
-	if err := srv.Start(port); err != nil {
+	if err := srv.Start(cfg.Port); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
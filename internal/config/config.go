@@ -0,0 +1,191 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/config/config.go
+Description: Application configuration. Reads the workspace identity and every
+AXIS_* setting from the environment into one typed struct, applies defaults, and
+reports all missing or malformed values together.
+*/
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/server"
+	"axis/internal/workspace"
+)
+
+const defaultPort = "8080"
+
+// Config is the complete runtime configuration for axis.
+type Config struct {
+	// AdminEmail is the Workspace admin impersonated at startup. Required.
+	AdminEmail string
+	// ServiceAccountEmail is the service account holding domain-wide delegation. Required.
+	ServiceAccountEmail string
+	// UserEmail is the operator looked up to verify the delegation. Required.
+	UserEmail string
+	// Port is the HTTP listen port. Empty means 8080.
+	Port string
+
+	// Server holds the settings passed to server.NewServer.
+	Server server.Config
+	// Exclusions hides Drive items by folder or MIME type.
+	Exclusions workspace.ExclusionRules
+	// SharedDriveID limits Drive listing to one shared drive. Empty searches all drives.
+	SharedDriveID string
+	// OwnedOnly limits Drive listing to files the impersonated user owns.
+	OwnedOnly bool
+	// Forms requests the Forms scope so form questions can be read. The scope
+	// must also be added to the delegation grant; without it forms stay listed
+	// but their content is unavailable.
+	Forms bool
+}
+
+// Load reads the configuration from the environment. The returned error lists
+// every missing required variable and every invalid value, not just the first.
+func Load() (Config, error) {
+	var errs []error
+	envInt := func(name string) int {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be an integer, got %q", name, raw))
+		}
+		return n
+	}
+	envBool := func(name string) bool {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return false
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be true or false, got %q", name, raw))
+		}
+		return b
+	}
+	envFloat := func(name string) float64 {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", name, raw))
+		}
+		return f
+	}
+	envStatuses := func(name string) []string {
+		raw := strings.TrimSpace(os.Getenv(name))
+		if !strings.HasSuffix(raw, ".json") {
+			return splitList(raw)
+		}
+		var statuses []string
+		data, err := os.ReadFile(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &statuses)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: cannot read statuses from %s: %w", name, raw, err))
+		}
+		return statuses
+	}
+	envDuration := func(name string) time.Duration {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return 0
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a duration such as 30s, got %q", name, raw))
+		}
+		return d
+	}
+
+	cfg := Config{
+		AdminEmail:          os.Getenv("ADMIN_EMAIL"),
+		ServiceAccountEmail: os.Getenv("SERVICE_ACCOUNT_EMAIL"),
+		UserEmail:           os.Getenv("USER_EMAIL"),
+		Port:                os.Getenv("PORT"),
+
+		Server: server.Config{
+			DefaultStatus: os.Getenv("AXIS_DEFAULT_STATUS"),
+			DefaultMode:   os.Getenv("AXIS_DEFAULT_MODE"),
+			WebhookURL:    os.Getenv("AXIS_WEBHOOK_URL"),
+			PersistMode:   os.Getenv("AXIS_PERSIST_MODE"),
+
+			DriveWebhookURL: os.Getenv("AXIS_DRIVE_WEBHOOK_URL"),
+			MaxSSEClients:   envInt("AXIS_MAX_SSE_CLIENTS"),
+			SSERetry:        time.Duration(envInt("AXIS_SSE_RETRY_MS")) * time.Millisecond,
+			SSEBuffer:       envInt("AXIS_SSE_BUFFER"),
+
+			DefaultStatusTypes: splitList(os.Getenv("AXIS_DEFAULT_STATUS_TYPES")),
+			MaxBodyBytes:       int64(envInt("AXIS_MAX_BODY")),
+			FetchTimeout:       envDuration("AXIS_FETCH_TIMEOUT"),
+			RefreshRetries:     envInt("AXIS_REFRESH_RETRIES"),
+			NoteCacheTTL:       envDuration("AXIS_NOTE_CACHE_TTL"),
+			CacheJitter:        envFloat("AXIS_CACHE_JITTER"),
+			DBFallback:         os.Getenv("AXIS_DB_FALLBACK"),
+			MinRefreshInterval: envDuration("AXIS_MIN_REFRESH_INTERVAL"),
+			DataDir:            os.Getenv("AXIS_DATA_DIR"),
+			VacuumInterval:     envDuration("AXIS_VACUUM_INTERVAL"),
+			Statuses:           envStatuses("AXIS_STATUSES"),
+			SkipWarmCache:      envBool("AXIS_SKIP_WARM_CACHE"),
+
+			ImpersonationToken:    os.Getenv("AXIS_IMPERSONATION_TOKEN"),
+			ImpersonationSubjects: splitList(os.Getenv("AXIS_IMPERSONATION_SUBJECTS")),
+		},
+		Exclusions: workspace.ExclusionRules{
+			Folders:   splitList(os.Getenv("AXIS_EXCLUDE_FOLDERS")),
+			MimeTypes: splitList(os.Getenv("AXIS_EXCLUDE_MIMETYPES")),
+		},
+		SharedDriveID: os.Getenv("AXIS_SHARED_DRIVE_ID"),
+		OwnedOnly:     envBool("AXIS_OWNED_ONLY"),
+		Forms:         envBool("AXIS_FORMS"),
+	}
+
+	var missing []string
+	for _, req := range []struct{ name, value string }{
+		{"ADMIN_EMAIL", cfg.AdminEmail},
+		{"SERVICE_ACCOUNT_EMAIL", cfg.ServiceAccountEmail},
+		{"USER_EMAIL", cfg.UserEmail},
+	} {
+		if req.value == "" {
+			missing = append(missing, req.name)
+		}
+	}
+	if len(missing) > 0 {
+		errs = append([]error{fmt.Errorf("missing required variables: %s", strings.Join(missing, ", "))}, errs...)
+	}
+
+	if err := cfg.Server.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = defaultPort
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// splitList splits a comma-separated setting into trimmed, non-empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/config/config_test.go
+Description: Unit tests for loading configuration from the environment.
+*/
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var allVars = []string{
+	"ADMIN_EMAIL", "SERVICE_ACCOUNT_EMAIL", "USER_EMAIL", "PORT",
+	"AXIS_DEFAULT_STATUS", "AXIS_WEBHOOK_URL", "AXIS_PERSIST_MODE",
+	"AXIS_DRIVE_WEBHOOK_URL", "AXIS_MAX_SSE_CLIENTS", "AXIS_SSE_RETRY_MS",
+	"AXIS_DEFAULT_STATUS_TYPES", "AXIS_MAX_BODY", "AXIS_FETCH_TIMEOUT",
+	"AXIS_EXCLUDE_FOLDERS", "AXIS_EXCLUDE_MIMETYPES", "AXIS_SHARED_DRIVE_ID",
+	"AXIS_OWNED_ONLY", "AXIS_DATA_DIR", "AXIS_VACUUM_INTERVAL",
+	"AXIS_STATUSES", "AXIS_DEFAULT_MODE", "AXIS_REFRESH_RETRIES",
+	"AXIS_SKIP_WARM_CACHE", "AXIS_SSE_BUFFER", "AXIS_NOTE_CACHE_TTL",
+	"AXIS_CACHE_JITTER", "AXIS_DB_FALLBACK",
+	"AXIS_MIN_REFRESH_INTERVAL", "AXIS_IMPERSONATION_TOKEN",
+	"AXIS_IMPERSONATION_SUBJECTS", "AXIS_FORMS",
+}
+
+// clearEnv blanks every variable Load reads for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range allVars {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadReportsEverythingMissing(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("USER_EMAIL", "user@example.com")
+	t.Setenv("AXIS_MAX_BODY", "lots")
+	t.Setenv("AXIS_PERSIST_MODE", "eventually")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected an error for missing variables")
+	}
+	msg := err.Error()
+	for _, want := range []string{"ADMIN_EMAIL", "SERVICE_ACCOUNT_EMAIL", "AXIS_MAX_BODY", "eventually"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected the error to mention %s, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "USER_EMAIL") {
+		t.Errorf("expected USER_EMAIL not to be reported, got:\n%s", msg)
+	}
+}
+
+func TestLoadAppliesDefaults(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("SERVICE_ACCOUNT_EMAIL", "sa@example.iam.gserviceaccount.com")
+	t.Setenv("USER_EMAIL", "user@example.com")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != "8080" {
+		t.Errorf("expected default port 8080, got %q", cfg.Port)
+	}
+	if cfg.Server.MaxSSEClients != 0 || cfg.Server.SSERetry != 0 || cfg.Server.MaxBodyBytes != 0 {
+		t.Errorf("expected server settings left at zero for server defaults, got %+v", cfg.Server)
+	}
+	if !cfg.Exclusions.Empty() {
+		t.Errorf("expected no exclusions, got %+v", cfg.Exclusions)
+	}
+	if cfg.Server.MinRefreshInterval != 0 || cfg.Server.CacheJitter != 0 {
+		t.Errorf("expected refresh throttling and jitter left at zero for server defaults, got %+v", cfg.Server)
+	}
+
+	t.Setenv("AXIS_MIN_REFRESH_INTERVAL", "-1s")
+	t.Setenv("AXIS_CACHE_JITTER", "-1")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("expected negative values to turn throttling and jitter off, got %v", err)
+	}
+	if cfg.Server.MinRefreshInterval != -time.Second || cfg.Server.CacheJitter != -1 {
+		t.Errorf("expected the negative values to be kept, got %+v", cfg.Server)
+	}
+}
+
+func TestLoadStatusesFromJSONFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("SERVICE_ACCOUNT_EMAIL", "sa@example.iam.gserviceaccount.com")
+	t.Setenv("USER_EMAIL", "user@example.com")
+
+	path := filepath.Join(t.TempDir(), "statuses.json")
+	if err := os.WriteFile(path, []byte(`["Pending", "Triaged", "Escalated"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AXIS_STATUSES", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Pending", "Triaged", "Escalated"}; !reflect.DeepEqual(cfg.Server.Statuses, want) {
+		t.Errorf("expected %v, got %v", want, cfg.Server.Statuses)
+	}
+
+	t.Setenv("AXIS_STATUSES", "Triaged,Escalated")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "Pending") {
+		t.Errorf("expected a set without Pending to be rejected, got %v", err)
+	}
+}
@@ -33,6 +33,23 @@ func NewDB(path string) (*DB, error) {
 	return d, nil
 }
 
+// NewMemoryDB opens a database that lives only in memory and is lost when closed.
+func NewMemoryDB() (*DB, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// Every connection to :memory: is a separate database; keep to one.
+	db.SetMaxOpenConns(1)
+
+	d := &DB{db: db}
+	if err := d.init(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
 // init creates the necessary tables if they don't exist.
 func (d *DB) init() error {
 	queries := []string{
@@ -44,6 +61,9 @@ func (d *DB) init() error {
 			id TEXT PRIMARY KEY,
 			status TEXT
 		);`,
+		`CREATE TABLE IF NOT EXISTS protected_items (
+			id TEXT PRIMARY KEY
+		);`,
 	}
 
 	for _, q := range queries {
@@ -52,9 +72,38 @@ func (d *DB) init() error {
 		}
 	}
 
+	if err := d.addColumn("item_statuses", "annotation", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
 	return nil
 }
 
+// addColumn adds a column to an existing table unless it is already present.
+func (d *DB) addColumn(table, column, definition string) error {
+	rows, err := d.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = d.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
 // Close closes the database connection.
 func (d *DB) Close() error {
 	return d.db.Close()
@@ -62,30 +111,48 @@ func (d *DB) Close() error {
 
 // SetMode updates the operational mode in the database.
 func (d *DB) SetMode(mode string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	_, err := d.db.Exec(`INSERT INTO app_state (key, value) VALUES ('mode', ?) 
 		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, mode)
 	return err
 }
 
-// GetMode retrieves the operational mode from the database.
+// GetMode retrieves the operational mode from the database. It returns an
+// empty string when no mode has been stored yet.
 func (d *DB) GetMode() (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	var mode string
 	err := d.db.QueryRow(`SELECT value FROM app_state WHERE key = 'mode'`).Scan(&mode)
 	if err == sql.ErrNoRows {
-		return "AUTO", nil
+		return "", nil
 	}
 	return mode, err
 }
 
 // SetStatus updates the status for a given item ID.
 func (d *DB) SetStatus(id, status string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	_, err := d.db.Exec(`INSERT INTO item_statuses (id, status) VALUES (?, ?) 
 		ON CONFLICT(id) DO UPDATE SET status = excluded.status`, id, status)
 	return err
 }
 
+// SetStatusAnnotation updates the status and annotation for a given item ID.
+func (d *DB) SetStatusAnnotation(id, status, annotation string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.db.Exec(`INSERT INTO item_statuses (id, status, annotation) VALUES (?, ?, ?) 
+		ON CONFLICT(id) DO UPDATE SET status = excluded.status, annotation = excluded.annotation`, id, status, annotation)
+	return err
+}
+
 // GetStatuses retrieves all item statuses as a map.
 func (d *DB) GetStatuses() (map[string]string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	rows, err := d.db.Query(`SELECT id, status FROM item_statuses`)
 	if err != nil {
 		return nil, err
@@ -103,8 +170,158 @@ func (d *DB) GetStatuses() (map[string]string, error) {
 	return statuses, nil
 }
 
+// GetAnnotations retrieves every non-empty item annotation as a map.
+func (d *DB) GetAnnotations() (map[string]string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	rows, err := d.db.Query(`SELECT id, annotation FROM item_statuses WHERE annotation != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := make(map[string]string)
+	for rows.Next() {
+		var id, annotation string
+		if err := rows.Scan(&id, &annotation); err != nil {
+			return nil, err
+		}
+		annotations[id] = annotation
+	}
+	return annotations, rows.Err()
+}
+
 // DeleteStatus removes a status entry for a given ID.
 func (d *DB) DeleteStatus(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	_, err := d.db.Exec(`DELETE FROM item_statuses WHERE id = ?`, id)
 	return err
 }
+
+// ClearStatuses removes every status entry and returns the number of rows deleted.
+func (d *DB) ClearStatuses() (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	res, err := d.db.Exec(`DELETE FROM item_statuses`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SetProtected adds id to, or removes it from, the set of items that may not be deleted.
+func (d *DB) SetProtected(id string, protected bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var err error
+	if protected {
+		_, err = d.db.Exec(`INSERT OR IGNORE INTO protected_items (id) VALUES (?)`, id)
+	} else {
+		_, err = d.db.Exec(`DELETE FROM protected_items WHERE id = ?`, id)
+	}
+	return err
+}
+
+// GetProtected retrieves the IDs of every protected item as a set.
+func (d *DB) GetProtected() (map[string]bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	rows, err := d.db.Query(`SELECT id FROM protected_items`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	protected := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		protected[id] = true
+	}
+	return protected, rows.Err()
+}
+
+// Vacuum rebuilds the database file to reclaim pages freed by deleted rows.
+func (d *DB) Vacuum() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.db.Exec(`VACUUM`)
+	return err
+}
+
+// Size returns the database size in bytes, computed from its page count.
+func (d *DB) Size() (int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var pages, pageSize int64
+	if err := d.db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := d.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// Ping verifies the database connection is usable.
+func (d *DB) Ping() error {
+	return d.db.Ping()
+}
+
+// legacyMigratedKey marks in app_state that the legacy JSON state was imported.
+const legacyMigratedKey = "legacy_json_migrated"
+
+// LegacyMigrated reports whether ImportLegacyState has completed before.
+func (d *DB) LegacyMigrated() (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM app_state WHERE key = ?`, legacyMigratedKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ImportLegacyState writes state read from the legacy JSON file in a single
+// transaction and marks the migration complete. Existing rows win: the mode and
+// each status are only inserted when absent, so newer database state is never
+// overwritten. It returns the number of statuses imported.
+func (d *DB) ImportLegacyState(mode string, statuses, annotations map[string]string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if mode != "" {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO app_state (key, value) VALUES ('mode', ?)`, mode); err != nil {
+			return 0, fmt.Errorf("failed to import mode: %w", err)
+		}
+	}
+	imported := 0
+	for id, status := range statuses {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO item_statuses (id, status, annotation) VALUES (?, ?, ?)`,
+			id, status, annotations[id])
+		if err != nil {
+			return 0, fmt.Errorf("failed to import status for %s: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			imported++
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO app_state (key, value) VALUES (?, 'true')
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, legacyMigratedKey); err != nil {
+		return 0, fmt.Errorf("failed to mark migration complete: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
@@ -60,3 +60,88 @@ func TestDB(t *testing.T) {
 		t.Errorf("expected note-1 to be deleted")
 	}
 }
+
+func TestClearStatuses(t *testing.T) {
+	dbPath := "test_clear.db"
+	defer os.Remove(dbPath)
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer db.Close()
+
+	db.SetStatus("note-1", "Active")
+	db.SetStatus("note-2", "Blocked")
+
+	n, err := db.ClearStatuses()
+	if err != nil {
+		t.Fatalf("failed to clear statuses: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 rows cleared, got %d", n)
+	}
+	statuses, _ := db.GetStatuses()
+	if len(statuses) != 0 {
+		t.Errorf("expected no statuses, got %v", statuses)
+	}
+}
+
+func TestStatusAnnotation(t *testing.T) {
+	dbPath := "test_annotation.db"
+	defer os.Remove(dbPath)
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+
+	if err := db.SetStatusAnnotation("note-1", "Blocked", "waiting on legal"); err != nil {
+		t.Fatalf("failed to set annotation: %v", err)
+	}
+	db.SetStatus("note-2", "Active")
+	// A plain status write leaves the annotation alone.
+	db.SetStatus("note-1", "Blocked")
+	db.Close()
+
+	// Reopening runs the schema migration again against the existing table.
+	db, err = NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer db.Close()
+
+	annotations, err := db.GetAnnotations()
+	if err != nil {
+		t.Fatalf("failed to get annotations: %v", err)
+	}
+	if len(annotations) != 1 || annotations["note-1"] != "waiting on legal" {
+		t.Errorf("expected only note-1 to be annotated, got %v", annotations)
+	}
+}
+
+func TestProtectedItems(t *testing.T) {
+	dbPath := "test_protected.db"
+	defer os.Remove(dbPath)
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer db.Close()
+
+	db.SetProtected("note-1", true)
+	db.SetProtected("note-1", true) // protecting twice is a no-op
+	db.SetProtected("note-2", true)
+	if err := db.SetProtected("note-2", false); err != nil {
+		t.Fatalf("failed to unprotect: %v", err)
+	}
+
+	protected, err := db.GetProtected()
+	if err != nil {
+		t.Fatalf("failed to get protected items: %v", err)
+	}
+	if len(protected) != 1 || !protected["note-1"] {
+		t.Errorf("expected only note-1 to be protected, got %v", protected)
+	}
+}
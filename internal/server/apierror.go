@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/apierror.go
+Description: Friendly Google API errors. Maps common googleapi failures to
+actionable messages for the UI while the raw error is kept in the logs.
+*/
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"axis/internal/workspace"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	msgMissingScope = "The service account is missing an API scope for this item. Add the scope (for notes, the Keep scope) to its domain-wide delegation grant."
+	msgAccessDenied = "Google denied access to this item for the impersonated user."
+	msgRateLimited  = "Google is rate limiting requests. Try again shortly."
+	msgNotFound     = "The item no longer exists."
+	msgUnauthorized = "Google rejected the service account credentials. Check the delegation setup."
+	msgUnavailable  = "Google is temporarily unavailable. Try again shortly."
+)
+
+// friendlyAPIError returns the status code and message to show for err, chosen
+// by its workspace.ErrorKind. Errors of unknown kind are passed through as a 500.
+func friendlyAPIError(err error) (int, string) {
+	var apiErr *googleapi.Error
+	errors.As(err, &apiErr)
+
+	switch workspace.KindOf(err) {
+	case workspace.KindRateLimited:
+		return http.StatusTooManyRequests, msgRateLimited
+	case workspace.KindNotFound:
+		return http.StatusNotFound, msgNotFound
+	case workspace.KindPermissionDenied:
+		switch {
+		case apiErr != nil && apiErr.Code == http.StatusUnauthorized:
+			return http.StatusBadGateway, msgUnauthorized
+		case apiErr != nil && isScopeError(apiErr):
+			return http.StatusForbidden, msgMissingScope
+		}
+		return http.StatusForbidden, msgAccessDenied
+	case workspace.KindTransient:
+		return http.StatusBadGateway, msgUnavailable
+	}
+	return http.StatusInternalServerError, err.Error()
+}
+
+func isScopeError(apiErr *googleapi.Error) bool {
+	return workspace.HasErrorReason(apiErr, "insufficientPermissions", "ACCESS_TOKEN_SCOPE_INSUFFICIENT") ||
+		strings.Contains(strings.ToLower(apiErr.Message), "insufficient authentication scopes")
+}
+
+// writeAPIError logs the raw error and responds with its friendly form.
+func (s *Server) writeAPIError(w http.ResponseWriter, err error) {
+	code, msg := friendlyAPIError(err)
+	s.health.recordAPIError(err, s.now())
+	s.logger.Error("google api request failed", "status", code, "error", err)
+	http.Error(w, msg, code)
+}
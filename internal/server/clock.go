@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/clock.go
+Description: Time source for the server. Cache expiry, pending deletes, and the
+poller read time through a clock so tests can advance it deterministically.
+*/
+package server
+
+import "time"
+
+// clock provides the current time and tickers.
+type clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) ticker
+}
+
+// ticker is the subset of *time.Ticker the server uses.
+type ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is the wall clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+
+func (r realTicker) Stop() { r.t.Stop() }
+
+// now returns the current time from the server's clock.
+func (s *Server) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// newTicker starts a ticker on the server's clock.
+func (s *Server) newTicker(d time.Duration) ticker {
+	if s.clock == nil {
+		return realClock{}.NewTicker(d)
+	}
+	return s.clock.NewTicker(d)
+}
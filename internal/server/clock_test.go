@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/clock_test.go
+Description: A fake clock for deterministic time in tests, and the tests that
+drive cache expiry and the poller with it.
+*/
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// fakeClock only moves when Advance is called.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward, firing each ticker whose period elapsed.
+// Like time.Ticker, ticks are dropped when the receiver is behind.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for !t.next.After(c.now) {
+			select {
+			case t.c <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+func (c *fakeClock) tickerCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tickers)
+}
+
+func TestCacheGoesStalePastTTL(t *testing.T) {
+	s := setupTestServer(t)
+	clk := newFakeClock()
+	s.clock = clk
+
+	s.applyRegistryItems([]workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}, clk.Now(), nil)
+	if _, fresh := s.cachedItemsFresh(); !fresh {
+		t.Fatal("expected a just-refreshed cache to be fresh")
+	}
+
+	clk.Advance(cacheTTL - time.Second)
+	if _, fresh := s.cachedItemsFresh(); !fresh {
+		t.Fatal("expected the cache to stay fresh before the TTL")
+	}
+
+	clk.Advance(2 * time.Second)
+	items, fresh := s.cachedItemsFresh()
+	if fresh {
+		t.Error("expected the cache to be stale past the TTL")
+	}
+	if len(items) != 1 {
+		t.Errorf("expected stale items to still be returned, got %d", len(items))
+	}
+}
+
+func TestPollerTicksOnFakeClock(t *testing.T) {
+	s := setupTestServer(t)
+	clk := newFakeClock()
+	s.clock = clk
+	ch := make(chan SSEMessage, 4)
+	s.clients[ch] = newSSEClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runPoller(ctx)
+	for deadline := time.Now().Add(time.Second); clk.tickerCount() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("poller never started its ticker")
+		}
+	}
+
+	clk.Advance(pollInterval)
+	select {
+	case msg := <-ch:
+		if msg.Event != "tick" {
+			t.Fatalf("expected a tick event, got %q", msg.Event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a tick after advancing the clock one interval")
+	}
+}
+
+func TestStateFlusherTicksOnFakeClock(t *testing.T) {
+	s := setupTestServer(t)
+	clk := newFakeClock()
+	s.clock = clk
+	s.statuses["notes/1"] = "Active"
+	s.markDirty("notes/1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runStateFlusher(ctx)
+	for deadline := time.Now().Add(time.Second); clk.tickerCount() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("flusher never started its ticker")
+		}
+	}
+
+	clk.Advance(s.persistEvery)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		statuses, err := s.db.GetStatuses()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statuses["notes/1"] == "Active" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a flush after advancing the clock one interval")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/csvexport.go
+Description: Registry filters shared by the list endpoints, and the CSV export
+for pulling the registry into a spreadsheet.
+*/
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"axis/internal/workspace"
+)
+
+// registryFilter narrows the registry to one item type and to items whose
+// title or snippet contains a query, ignoring case. Zero fields match everything.
+type registryFilter struct {
+	itemType string
+	query    string
+}
+
+// parseRegistryFilter reads ?type= and ?q=.
+func parseRegistryFilter(r *http.Request) (registryFilter, error) {
+	f := registryFilter{
+		itemType: strings.TrimSpace(r.URL.Query().Get("type")),
+		query:    strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q"))),
+	}
+	if f.itemType != "" && !itemTypes[f.itemType] {
+		return f, fmt.Errorf("unknown type %q", f.itemType)
+	}
+	return f, nil
+}
+
+func (f registryFilter) match(item workspace.RegistryItem) bool {
+	if f.itemType != "" && item.Type != f.itemType {
+		return false
+	}
+	if f.query != "" &&
+		!strings.Contains(strings.ToLower(item.Title), f.query) &&
+		!strings.Contains(strings.ToLower(item.Snippet), f.query) {
+		return false
+	}
+	return true
+}
+
+// apply returns the matching items, reusing the backing array of items.
+func (f registryFilter) apply(items []workspace.RegistryItem) []workspace.RegistryItem {
+	if f == (registryFilter{}) {
+		return items
+	}
+	kept := items[:0]
+	for _, item := range items {
+		if f.match(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// registryCSVHeader names the columns of the CSV export.
+var registryCSVHeader = []string{"id", "type", "title", "status", "snippet"}
+
+// csvText neutralizes a cell a spreadsheet would evaluate as a formula by
+// prefixing a quote. Titles and snippets come from documents others can share
+// with the user, so they are not trusted.
+func csvText(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// exportItems returns the registry to export, refreshing a stale cache first.
+// When the refresh fails with nothing cached it writes the error and reports
+// false, so an outage is not exported as an empty registry.
+func (s *Server) exportItems(w http.ResponseWriter, r *http.Request) ([]workspace.RegistryItem, bool) {
+	items, fresh := s.cachedItemsFresh()
+	if fresh {
+		return items, true
+	}
+	err := s.refreshRegistryCache(r.Context())
+	items, _ = s.cachedItemsFresh()
+	if err != nil && len(items) == 0 {
+		s.writeAPIError(w, err)
+		return nil, false
+	}
+	return items, true
+}
+
+// handleRegistryExportCSV streams the enriched registry as CSV, one row per item,
+// filtered by ?type= and ?q= like /api/registry.
+func (s *Server) handleRegistryExportCSV(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	filter, err := parseRegistryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	items, ok := s.exportItems(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="registry.csv"`)
+	flusher, _ := w.(http.Flusher)
+	cw := csv.NewWriter(w)
+	cw.Write(registryCSVHeader)
+	for _, item := range items {
+		if !filter.match(item) {
+			continue
+		}
+		s.modeMu.RLock()
+		item = s.enrichItemLocked(item)
+		s.modeMu.RUnlock()
+
+		cw.Write([]string{item.ID, item.Type, csvText(item.Title), item.Status, csvText(item.Snippet)})
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			s.logger.Warn("registry CSV export aborted", "error", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	cw.Flush()
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/delete.go
+Description: Delete bookkeeping shared by the per-type delete handlers. Tracks
+recently deleted items so a lagging upstream list (Drive's eventually consistent
+index in particular) cannot flicker them back into the registry.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+const (
+	pendingDeleteTTL = 30 * time.Second
+
+	// maxBatchDelete caps the ids accepted by one batch delete request.
+	maxBatchDelete = 100
+	// batchDeleteWorkers bounds concurrent upstream deletes within a batch.
+	batchDeleteWorkers = 5
+)
+
+// errProtected explains a delete refused because the item is protected.
+const errProtected = "item is protected from deletion; unprotect it first"
+
+var deletableTypes = map[string]bool{
+	"keep":  true,
+	"doc":   true,
+	"sheet": true,
+	"form":  true,
+	"gmail": true,
+}
+
+// deleteItem removes a single item upstream using the Service method for its type.
+func (s *Server) deleteItem(ctx context.Context, itemType, id string) error {
+	ws := s.workspace()
+	switch itemType {
+	case "keep":
+		return ws.DeleteNote(ctx, id)
+	case "doc":
+		return ws.DeleteDoc(id)
+	case "sheet":
+		return ws.DeleteSheet(id)
+	case "form":
+		return ws.DeleteForm(id)
+	case "gmail":
+		return ws.TrashGmailThread(id)
+	default:
+		return fmt.Errorf("unsupported item type %q", itemType)
+	}
+}
+
+// getItemType looks up the cached type for id.
+func (s *Server) getItemType(id string) string {
+	s.registryCache.mu.RLock()
+	defer s.registryCache.mu.RUnlock()
+	for _, item := range s.registryCache.items {
+		if item.ID == id {
+			return item.Type
+		}
+	}
+	return ""
+}
+
+// sharedNoteError explains why a shared note was not deleted, or returns "" when
+// the delete may proceed. Collaborators are read from the note upstream rather
+// than the registry cache, so notes that are not cached are guarded too. With
+// force nothing is fetched.
+func (s *Server) sharedNoteError(ctx context.Context, id string, force bool) (string, error) {
+	if force {
+		return "", nil
+	}
+	note, err := s.workspace().GetNote(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if n := workspace.NoteCollaborators(note); n > 0 {
+		return fmt.Sprintf("note is shared with %d collaborator(s); pass force=true to delete it", n), nil
+	}
+	return "", nil
+}
+
+// forgetStatus drops the in-memory and persisted status for a deleted item. It
+// holds persistMu so an in-flight flush cannot write the status back.
+func (s *Server) forgetStatus(id string) {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	s.modeMu.Lock()
+	delete(s.statuses, id)
+	delete(s.annotations, id)
+	delete(s.dirty, id)
+	s.modeMu.Unlock()
+
+	if err := s.db.DeleteStatus(id); err != nil {
+		s.logger.Error("failed to delete status", "id", id, "error", err)
+	}
+}
+
+// handleDeleteItem deletes any registry item. The type comes from ?type= or,
+// when omitted, from the cached registry entry. Deletes require POST (or DELETE)
+// and MANUAL mode. Shared Keep notes also require ?force=true, and protected
+// items are refused with 403.
+func (s *Server) handleDeleteItem(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, r.URL.Query().Get("type"))
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, "keep")
+}
+
+func (s *Server) handleDeleteSheet(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, "sheet")
+}
+
+func (s *Server) handleDeleteDoc(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, "doc")
+}
+
+func (s *Server) handleDeleteForm(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, "form")
+}
+
+func (s *Server) handleDeleteGmailThread(w http.ResponseWriter, r *http.Request) {
+	s.deleteItemOfType(w, r, "gmail")
+}
+
+func (s *Server) deleteItemOfType(w http.ResponseWriter, r *http.Request, itemType string) {
+	if !allowMethods(w, r, http.MethodPost, http.MethodDelete) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if !s.isManualMode() {
+		http.Error(w, "delete requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+
+	if itemType == "" {
+		itemType = s.getItemType(id)
+		if itemType == "" {
+			http.Error(w, "unknown item type; pass ?type=", http.StatusBadRequest)
+			return
+		}
+	}
+	if !deletableTypes[itemType] {
+		http.Error(w, "unsupported item type", http.StatusBadRequest)
+		return
+	}
+
+	// Check sharing under the lock, as the batch and edit paths do, so a note
+	// cannot become shared between the check and the delete.
+	unlock := s.itemLocks.lock(id)
+	if s.isProtected(id) {
+		unlock()
+		http.Error(w, errProtected, http.StatusForbidden)
+		return
+	}
+	if itemType == "keep" {
+		msg, err := s.sharedNoteError(r.Context(), id, truthyParam(r.URL.Query().Get("force")))
+		if err != nil {
+			unlock()
+			s.writeAPIError(w, err)
+			return
+		}
+		if msg != "" {
+			unlock()
+			http.Error(w, msg, http.StatusConflict)
+			return
+		}
+	}
+	if err := s.deleteItem(r.Context(), itemType, id); err != nil {
+		unlock()
+		if errors.Is(err, workspace.ErrExcluded) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		s.writeAPIError(w, err)
+		return
+	}
+	s.markPendingDelete(id)
+	s.forgetStatus(id)
+	unlock()
+
+	// The item is already gone from the cache, so a throttled refresh loses nothing.
+	if !s.forceRefresh(r.Context()) {
+		w.Header().Set(throttledHeader, "true")
+	}
+	s.broadcastRegistry()
+	w.WriteHeader(http.StatusOK)
+}
+
+// BatchDeleteResult reports the outcome of deleting one id in a batch.
+type BatchDeleteResult struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleBatchDelete deletes the Keep notes named by a JSON array of ids. Deletes
+// run concurrently, and the response is 207 Multi-Status with one result per id.
+// Shared notes are refused with 409 unless ?force=true, and protected notes with 403.
+func (s *Server) handleBatchDelete(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "delete requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+
+	if !requireJSON(w, r) {
+		return
+	}
+	var ids []string
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		http.Error(w, "invalid JSON body; want an array of ids", bodyErrorStatus(err))
+		return
+	}
+	if len(ids) == 0 {
+		http.Error(w, "no ids given", http.StatusBadRequest)
+		return
+	}
+	if len(ids) > maxBatchDelete {
+		http.Error(w, fmt.Sprintf("too many ids (max %d)", maxBatchDelete), http.StatusBadRequest)
+		return
+	}
+
+	force := truthyParam(r.URL.Query().Get("force"))
+	results := make([]BatchDeleteResult, len(ids))
+	seen := make(map[string]bool, len(ids))
+	sem := make(chan struct{}, batchDeleteWorkers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		results[i].ID = id
+		if id == "" {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = "missing id"
+			continue
+		}
+		if seen[id] {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = "duplicate id"
+			continue
+		}
+		seen[id] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *BatchDeleteResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.batchDeleteOne(r.Context(), res, force)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	deleted := 0
+	for _, res := range results {
+		if res.Status == http.StatusOK {
+			deleted++
+		}
+	}
+	s.logger.Info("batch delete", "requested", len(ids), "deleted", deleted)
+	if deleted > 0 {
+		if !s.forceRefresh(r.Context()) {
+			w.Header().Set(throttledHeader, "true")
+		}
+		s.broadcastRegistry()
+	}
+
+	s.writeJSON(w, http.StatusMultiStatus, results)
+}
+
+// batchDeleteOne deletes a single note for handleBatchDelete, recording the outcome in res.
+func (s *Server) batchDeleteOne(ctx context.Context, res *BatchDeleteResult, force bool) {
+	unlock := s.itemLocks.lock(res.ID)
+	defer unlock()
+
+	if s.isProtected(res.ID) {
+		res.Status, res.Error = http.StatusForbidden, errProtected
+		return
+	}
+	msg, err := s.sharedNoteError(ctx, res.ID, force)
+	if err != nil {
+		code, friendly := friendlyAPIError(err)
+		s.logger.Error("batch delete failed", "id", res.ID, "status", code, "error", err)
+		res.Status, res.Error = code, friendly
+		return
+	}
+	if msg != "" {
+		res.Status, res.Error = http.StatusConflict, msg
+		return
+	}
+
+	if err := s.deleteItem(ctx, "keep", res.ID); err != nil {
+		code, msg := friendlyAPIError(err)
+		s.logger.Error("batch delete failed", "id", res.ID, "status", code, "error", err)
+		res.Status, res.Error = code, msg
+		return
+	}
+	s.markPendingDelete(res.ID)
+	s.forgetStatus(res.ID)
+	res.Status = http.StatusOK
+}
+
+// markPendingDelete hides id from the registry until a refresh confirms it is gone
+// or pendingDeleteTTL elapses, and drops it from the current cache immediately.
+func (s *Server) markPendingDelete(id string) {
+	s.pendingDeletesMu.Lock()
+	if s.pendingDeletes == nil {
+		s.pendingDeletes = make(map[string]time.Time)
+	}
+	s.pendingDeletes[id] = s.now().Add(pendingDeleteTTL)
+	s.pendingDeletesMu.Unlock()
+	s.forgetNote(id)
+	s.forgetSheetValues(id)
+
+	s.registryCache.mu.Lock()
+	kept := s.registryCache.items[:0]
+	for _, item := range s.registryCache.items {
+		if item.ID != id {
+			kept = append(kept, item)
+		}
+	}
+	s.registryCache.items = kept
+	delete(s.registryCache.hashes, id)
+	s.registryCache.mu.Unlock()
+}
+
+// isPendingDelete reports whether id was deleted recently and is still hidden.
+func (s *Server) isPendingDelete(id string) bool {
+	s.pendingDeletesMu.Lock()
+	defer s.pendingDeletesMu.Unlock()
+	until, ok := s.pendingDeletes[id]
+	return ok && s.now().Before(until)
+}
+
+// filterPendingDeletes removes recently deleted items from a fresh upstream list.
+// Entries are forgotten once the upstream list no longer returns them or they expire.
+func (s *Server) filterPendingDeletes(items []workspace.RegistryItem) []workspace.RegistryItem {
+	s.pendingDeletesMu.Lock()
+	defer s.pendingDeletesMu.Unlock()
+
+	if len(s.pendingDeletes) == 0 {
+		return items
+	}
+
+	now := s.now()
+	seen := make(map[string]bool, len(s.pendingDeletes))
+	res := make([]workspace.RegistryItem, 0, len(items))
+	for _, item := range items {
+		if until, ok := s.pendingDeletes[item.ID]; ok && now.Before(until) {
+			seen[item.ID] = true
+			s.logger.Info("hiding item pending deletion", "id", item.ID)
+			continue
+		}
+		res = append(res, item)
+	}
+
+	for id := range s.pendingDeletes {
+		if !seen[id] {
+			delete(s.pendingDeletes, id)
+		}
+	}
+	return res
+}
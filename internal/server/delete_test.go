@@ -0,0 +1,416 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/delete_test.go
+Description: Unit tests for delete handling, including the pending-deletion
+filter that masks Drive's eventually consistent file listing.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestDeletedItemHiddenWhileDriveLags(t *testing.T) {
+	var lagging atomic.Bool
+	lagging.Store(true)
+
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		case strings.Contains(r.URL.Query().Get("q"), "document") && lagging.Load():
+			w.Write([]byte(`{"files": [{"id": "doc-1", "name": "Deleted Doc"}]}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+
+	s.refreshRegistryCache(context.Background())
+	if items, _ := s.cachedItemsFresh(); len(items) != 1 {
+		t.Fatalf("expected the doc to be cached before delete, got %+v", items)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleDeleteDoc(rr, httptest.NewRequest("POST", "/api/docs/delete?id=doc-1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	// Drive still lists the file; it must stay hidden.
+	s.refreshRegistryCache(context.Background())
+	items, _ := s.cachedItemsFresh()
+	for _, item := range s.enrichItems(items) {
+		if item.ID == "doc-1" {
+			t.Fatal("expected deleted doc to be hidden while Drive lags")
+		}
+	}
+
+	// Once Drive catches up, the pending entry is cleared.
+	lagging.Store(false)
+	s.refreshRegistryCache(context.Background())
+	s.pendingDeletesMu.Lock()
+	remaining := len(s.pendingDeletes)
+	s.pendingDeletesMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("expected pending deletion to clear after a confirming refresh, got %d", remaining)
+	}
+}
+
+func TestUnifiedDeleteDispatchesByType(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			w.Write([]byte(`{"notes": []}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+	seed := func() {
+		s.registryCache.items = []workspace.RegistryItem{
+			{ID: "notes/abc", Type: "keep", Title: "Note"},
+			{ID: "doc-1", Type: "doc", Title: "Doc"},
+			{ID: "sheet-1", Type: "sheet", Title: "Sheet"},
+		}
+	}
+	seed()
+	s.statuses["notes/abc"] = "Complete"
+
+	// Deletes are refused outside MANUAL mode for every type.
+	for _, id := range []string{"notes/abc", "doc-1", "sheet-1"} {
+		rr := httptest.NewRecorder()
+		s.handleDeleteItem(rr, httptest.NewRequest("POST", "/api/registry/delete?id="+id, nil))
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected 403 for %s in AUTO mode, got %v", id, rr.Code)
+		}
+	}
+
+	s.mode = "MANUAL"
+	cases := []struct {
+		url      string
+		wantPath string
+	}{
+		{"/api/registry/delete?id=notes/abc", "/v1/notes/abc"},
+		{"/api/registry/delete?id=doc-1", "/files/doc-1"},
+		{"/api/registry/delete?id=sheet-1&type=sheet", "/files/sheet-1"},
+	}
+	for _, tc := range cases {
+		// Each delete refreshes from the (empty) stub, so restore the cache first.
+		seed()
+		rr := httptest.NewRecorder()
+		s.handleDeleteItem(rr, httptest.NewRequest("POST", tc.url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %v: %s", tc.url, rr.Code, rr.Body.String())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deleted) != len(cases) {
+		t.Fatalf("expected %d upstream deletes, got %v", len(cases), deleted)
+	}
+	for i, tc := range cases {
+		if deleted[i] != tc.wantPath {
+			t.Errorf("expected delete %d to hit %s, got %s", i, tc.wantPath, deleted[i])
+		}
+	}
+
+	if _, ok := s.statuses["notes/abc"]; ok {
+		t.Error("expected the deleted note's status to be removed")
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleDeleteItem(rr, httptest.NewRequest("POST", "/api/registry/delete?id=unknown", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an item of unknown type, got %v", rr.Code)
+	}
+}
+
+func TestConcurrentDeleteAndStatusStayConsistent(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			time.Sleep(time.Millisecond)
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": [{"name": "notes/other", "title": "Other"}], "files": []}`))
+	}))
+
+	for i := 0; i < 25; i++ {
+		id := fmt.Sprintf("notes/n%d", i)
+		s.statuses[id] = "Pending"
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			s.handleDelete(rr, httptest.NewRequest("POST", "/api/notes/delete?id="+id, nil))
+			if rr.Code != http.StatusOK {
+				t.Errorf("%s: expected delete to succeed, got %v", id, rr.Code)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id="+id+"&status=Active", nil))
+			if rr.Code != http.StatusOK && rr.Code != http.StatusConflict {
+				t.Errorf("%s: unexpected status response %v", id, rr.Code)
+			}
+		}()
+		wg.Wait()
+
+		s.modeMu.RLock()
+		_, ok := s.statuses[id]
+		s.modeMu.RUnlock()
+		if ok {
+			t.Errorf("%s: expected no status to survive the delete", id)
+		}
+	}
+
+	s.flushState()
+	stored, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := range stored {
+		if strings.HasPrefix(id, "notes/n") {
+			t.Errorf("expected no persisted status for deleted %s", id)
+		}
+	}
+}
+
+func TestBatchDeletePartialSuccess(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	var deletes atomic.Int32
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/notes/bad":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "Requested entity was not found."}}`))
+		case r.Method == http.MethodDelete:
+			deletes.Add(1)
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": [{"name": "notes/bad", "title": "Bad"}]}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+	for _, id := range []string{"notes/a", "notes/bad", "notes/c"} {
+		s.statuses[id] = "Complete"
+	}
+
+	body := strings.NewReader(`["notes/a", "notes/bad", "notes/c"]`)
+	rr := httptest.NewRecorder()
+	s.handleBatchDelete(rr, jsonRequest("POST", "/api/notes/batch-delete", body))
+	if rr.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var results []BatchDeleteResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"notes/a": http.StatusOK, "notes/bad": http.StatusNotFound, "notes/c": http.StatusOK}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for _, res := range results {
+		if res.Status != want[res.ID] {
+			t.Errorf("%s: expected status %d, got %d (%s)", res.ID, want[res.ID], res.Status, res.Error)
+		}
+	}
+	if deletes.Load() != 2 {
+		t.Errorf("expected 2 upstream deletes, got %d", deletes.Load())
+	}
+	if _, ok := s.statuses["notes/a"]; ok {
+		t.Error("expected the deleted note's status to be removed")
+	}
+	if _, ok := s.statuses["notes/bad"]; !ok {
+		t.Error("expected the failed note to keep its status")
+	}
+}
+
+func TestSharedNoteDeleteRequiresForce(t *testing.T) {
+	var deletes atomic.Int32
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		const shared = `{"name": "notes/shared", "title": "Team Plan", "permissions": [
+			{"name": "notes/shared/permissions/1", "role": "OWNER", "email": "me@example.com"},
+			{"name": "notes/shared/permissions/2", "role": "WRITER", "email": "a@example.com"},
+			{"name": "notes/shared/permissions/3", "role": "WRITER", "email": "b@example.com"},
+			{"name": "notes/shared/permissions/4", "role": "WRITER", "email": "gone@example.com", "deleted": true}
+		]}`
+		switch r.URL.Path {
+		case "/v1/notes":
+			w.Write([]byte(`{"notes": [` + shared + `]}`))
+		case "/v1/notes/shared":
+			w.Write([]byte(shared))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+
+	// Before any listing, as on a cold start or after a subject switch, the
+	// note's own permissions still guard it.
+	rr := httptest.NewRecorder()
+	s.handleDelete(rr, httptest.NewRequest("POST", "/api/notes/delete?id=notes/shared&type=keep", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for an uncached shared note, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	s.refreshRegistryCache(context.Background())
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 1 || !items[0].Shared || items[0].Collaborators != 2 {
+		t.Fatalf("expected a shared note with 2 collaborators, got %+v", items)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleDelete(rr, httptest.NewRequest("POST", "/api/notes/delete?id=notes/shared", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without force, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("expected no upstream delete for a guarded note")
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleDelete(rr, httptest.NewRequest("POST", "/api/notes/delete?id=notes/shared&force=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 with force, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if deletes.Load() != 1 {
+		t.Errorf("expected one upstream delete, got %d", deletes.Load())
+	}
+}
+
+func TestProtectedItemDeleteIsRefused(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	var deletes atomic.Int32
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "Critical"}]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+	s.refreshRegistryCache(context.Background())
+
+	rr := httptest.NewRecorder()
+	s.handleProtect(rr, httptest.NewRequest("POST", "/api/registry/protect?id=notes/1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 from protect, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if items := s.currentRegistry(context.Background()); len(items) != 1 || !items[0].Protected {
+		t.Fatalf("expected the registry item to be marked protected, got %+v", items)
+	}
+	if stored, _ := s.db.GetProtected(); !stored["notes/1"] {
+		t.Error("expected the protection to be persisted")
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleDeleteItem(rr, httptest.NewRequest("POST", "/api/registry/delete?id=notes/1&type=keep&force=true", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a protected item in MANUAL mode, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleBatchDelete(rr, jsonRequest("POST", "/api/notes/batch-delete", strings.NewReader(`["notes/1"]`)))
+	var results []BatchDeleteResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != http.StatusForbidden {
+		t.Errorf("expected the batch entry to be refused with 403, got %+v", results)
+	}
+
+	// An edit recreates the note and deletes the original, so it is refused too.
+	rr = httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/1", strings.NewReader(`{"content": "x"}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 editing a protected note, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if deletes.Load() != 0 {
+		t.Fatalf("expected no upstream delete for a protected item, got %d", deletes.Load())
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleUnprotect(rr, httptest.NewRequest("POST", "/api/registry/unprotect?id=notes/1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 from unprotect, got %v", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	s.handleDelete(rr, httptest.NewRequest("POST", "/api/notes/delete?id=notes/1", nil))
+	if rr.Code != http.StatusOK || deletes.Load() != 1 {
+		t.Errorf("expected the unprotected note to delete, got %v with %d deletes", rr.Code, deletes.Load())
+	}
+}
+
+func TestUnprotectRequiresManualMode(t *testing.T) {
+	s := setupTestServer(t)
+	if err := s.setProtected("notes/1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleUnprotect(rr, httptest.NewRequest("POST", "/api/registry/unprotect?id=notes/1", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 unprotecting in AUTO mode, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if !s.isProtected("notes/1") {
+		t.Error("expected the item to stay protected in memory")
+	}
+	if stored, _ := s.db.GetProtected(); !stored["notes/1"] {
+		t.Error("expected the protection to stay persisted")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/diff.go
+Description: Change tracking between registry refreshes. Compares the previous
+cache snapshot with a freshly fetched one and publishes a compact summary of
+added, removed, and renamed items over SSE.
+*/
+package server
+
+import (
+	"encoding/json"
+
+	"axis/internal/workspace"
+)
+
+// RegistryRename records a title change for an item present in both snapshots.
+type RegistryRename struct {
+	ID  string `json:"id"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// RegistryDiff summarizes what changed between two registry snapshots.
+type RegistryDiff struct {
+	Added   []string         `json:"added"`
+	Removed []string         `json:"removed"`
+	Renamed []RegistryRename `json:"renamed"`
+}
+
+// Empty reports whether the diff carries no changes.
+func (d RegistryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0
+}
+
+// diffRegistry compares two snapshots by ID. Results follow the order of the
+// snapshot each ID was found in, so the output is stable for a given input.
+func diffRegistry(previous, current []workspace.RegistryItem) RegistryDiff {
+	prevByID := make(map[string]workspace.RegistryItem, len(previous))
+	for _, item := range previous {
+		prevByID[item.ID] = item
+	}
+	currIDs := make(map[string]bool, len(current))
+
+	diff := RegistryDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Renamed: []RegistryRename{},
+	}
+	for _, item := range current {
+		currIDs[item.ID] = true
+		old, ok := prevByID[item.ID]
+		if !ok {
+			diff.Added = append(diff.Added, item.ID)
+			continue
+		}
+		if old.Title != item.Title {
+			diff.Renamed = append(diff.Renamed, RegistryRename{ID: item.ID, Old: old.Title, New: item.Title})
+		}
+	}
+	for _, item := range previous {
+		if !currIDs[item.ID] {
+			diff.Removed = append(diff.Removed, item.ID)
+		}
+	}
+	return diff
+}
+
+func (s *Server) broadcastRegistryDiff(diff RegistryDiff) {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		s.logger.Error("registry diff marshal failed", "error", err)
+		return
+	}
+	s.broadcast(SSEMessage{Event: "registry-diff", Data: data})
+}
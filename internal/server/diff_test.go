@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/diff_test.go
+Description: Unit tests for registry change tracking between cache refreshes.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshEmitsRegistryDiff(t *testing.T) {
+	var calls int32
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v1/notes" {
+			w.Write([]byte(`{"files": []}`))
+			return
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"notes": [
+				{"name": "notes/keep", "title": "Same"},
+				{"name": "notes/rename", "title": "Before"},
+				{"name": "notes/gone", "title": "Removed"}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"notes": [
+			{"name": "notes/keep", "title": "Same"},
+			{"name": "notes/rename", "title": "After"},
+			{"name": "notes/new", "title": "Added"}
+		]}`))
+	}))
+
+	ch := make(chan SSEMessage, 32)
+	s.clients[ch] = newSSEClient()
+
+	s.refreshRegistryCache(context.Background())
+	for len(ch) > 0 {
+		if msg := <-ch; msg.Event == "registry-diff" {
+			t.Fatal("expected no diff on the initial load")
+		}
+	}
+
+	s.refreshRegistryCache(context.Background())
+
+	var diff *RegistryDiff
+	for len(ch) > 0 {
+		msg := <-ch
+		if msg.Event != "registry-diff" {
+			continue
+		}
+		diff = &RegistryDiff{}
+		if err := json.Unmarshal(msg.Data, diff); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff == nil {
+		t.Fatal("expected a registry-diff event after the second refresh")
+	}
+
+	want := RegistryDiff{
+		Added:   []string{"notes/new"},
+		Removed: []string{"notes/gone"},
+		Renamed: []RegistryRename{{ID: "notes/rename", Old: "Before", New: "After"}},
+	}
+	if !reflect.DeepEqual(*diff, want) {
+		t.Errorf("expected diff %+v, got %+v", want, *diff)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/drivepush.go
+Description: Drive push notification receiver. Keeps a Drive changes watch
+channel registered (renewing it before expiry) and turns incoming notifications
+into an immediate registry refresh instead of waiting for the poll countdown.
+*/
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+const (
+	driveChannelTTL      = time.Hour
+	driveRenewMargin     = 5 * time.Minute
+	driveWatchRetryDelay = time.Minute
+)
+
+// requestRefresh asks the poller for an immediate refresh. Bursts are coalesced.
+func (s *Server) requestRefresh() {
+	select {
+	case s.refreshSignal <- struct{}{}:
+	default:
+	}
+}
+
+// handleDriveWebhook receives Drive change notifications. Google sends a "sync"
+// message when the channel is created, which carries no change and is ignored.
+func (s *Server) handleDriveWebhook(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	token := r.Header.Get("X-Goog-Channel-Token")
+	if s.driveToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.driveToken)) != 1 {
+		s.logger.Warn("rejected drive notification with bad token", "channel", r.Header.Get("X-Goog-Channel-ID"))
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	state := r.Header.Get("X-Goog-Resource-State")
+	if state != "sync" {
+		s.logger.Info("drive change notification", "state", state, "channel", r.Header.Get("X-Goog-Channel-ID"))
+		s.requestRefresh()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// requestRewatch asks runDriveWatch to re-register the channel now, because the
+// subject it watches for has changed. Bursts are coalesced.
+func (s *Server) requestRewatch() {
+	select {
+	case s.driveRewatch <- struct{}{}:
+	default:
+	}
+}
+
+// runDriveWatch registers the Drive changes channel and renews it ahead of expiry
+// or when the impersonated subject changes, until ctx is canceled, at which
+// point the channel is stopped. Each channel is stopped with the service that
+// created it, since another subject's credentials cannot stop it.
+func (s *Server) runDriveWatch(ctx context.Context) {
+	var current *workspace.DriveChannel
+	var currentWS *workspace.Service
+	defer func() {
+		if current == nil {
+			return
+		}
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := currentWS.StopDriveChannel(stopCtx, current); err != nil {
+			s.logger.Warn("failed to stop drive channel", "error", err)
+		}
+	}()
+
+	for {
+		ws := s.workspace()
+		next, err := ws.WatchDriveChanges(ctx, s.driveWebhookURL, s.driveToken, driveChannelTTL)
+		wait := driveWatchRetryDelay
+		if err != nil {
+			s.logger.Error("drive watch registration failed, relying on polling", "error", err)
+		} else {
+			if current != nil {
+				if err := currentWS.StopDriveChannel(ctx, current); err != nil {
+					s.logger.Warn("failed to stop previous drive channel", "error", err)
+				}
+			}
+			current, currentWS = next, ws
+			wait = current.Expiration.Sub(s.now()) - driveRenewMargin
+			if wait < driveWatchRetryDelay {
+				wait = driveWatchRetryDelay
+			}
+			s.logger.Info("drive watch channel active", "channel", current.ID, "expires", current.Expiration)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-s.driveRewatch:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newChannelToken returns the random secret Drive echoes back on every
+// notification, so forged posts to the webhook can be told apart.
+func newChannelToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/drivepush_test.go
+Description: Unit tests for the Drive push notification receiver and the
+changes-feed gated poll.
+*/
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestDriveWebhookTriggersRefresh(t *testing.T) {
+	s, err := NewServer(nil, nil, Config{DataDir: t.TempDir(), DriveWebhookURL: "https://axis.example.com/api/drive/webhook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+	s.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	if s.driveWebhookURL == "" || s.driveToken == "" {
+		t.Fatalf("expected a configured webhook URL to enable push, got url %q token %q", s.driveWebhookURL, s.driveToken)
+	}
+	secret := s.driveToken
+
+	notify := func(token, state string) int {
+		req := httptest.NewRequest("POST", "/api/drive/webhook", nil)
+		req.Header.Set("X-Goog-Channel-ID", "axis-test")
+		req.Header.Set("X-Goog-Channel-Token", token)
+		req.Header.Set("X-Goog-Resource-State", state)
+		rr := httptest.NewRecorder()
+		s.handleDriveWebhook(rr, req)
+		return rr.Code
+	}
+
+	// The initial sync message must not trigger a refresh.
+	if code := notify(secret, "sync"); code != http.StatusOK {
+		t.Fatalf("expected 200 for sync, got %v", code)
+	}
+	if len(s.refreshSignal) != 0 {
+		t.Fatal("expected no refresh for a sync notification")
+	}
+
+	// A forged notification is rejected.
+	if code := notify("wrong", "change"); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a bad token, got %v", code)
+	}
+	if len(s.refreshSignal) != 0 {
+		t.Fatal("expected no refresh for a rejected notification")
+	}
+
+	// Real changes request a refresh; bursts coalesce into one pending signal.
+	if code := notify(secret, "change"); code != http.StatusOK {
+		t.Fatalf("expected 200 for change, got %v", code)
+	}
+	notify(secret, "change")
+	if len(s.refreshSignal) != 1 {
+		t.Errorf("expected exactly one pending refresh, got %d", len(s.refreshSignal))
+	}
+}
+
+func TestDrivePushOffWithoutWebhookURL(t *testing.T) {
+	s, err := NewServer(nil, nil, Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+	s.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	if s.driveWebhookURL != "" || s.driveToken != "" {
+		t.Fatalf("expected push to stay off, got url %q token %q", s.driveWebhookURL, s.driveToken)
+	}
+
+	// Without a channel token every notification is refused.
+	req := httptest.NewRequest("POST", "/api/drive/webhook", nil)
+	rr := httptest.NewRecorder()
+	s.handleDriveWebhook(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without a channel token, got %v", rr.Code)
+	}
+}
+
+// channelStub serves the Drive watch endpoints and counts the channels it has
+// created and stopped.
+type channelStub struct {
+	watches, stops atomic.Int32
+}
+
+func (c *channelStub) workspace(t *testing.T) *workspace.Service {
+	return newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/changes/startPageToken":
+			w.Write([]byte(`{"startPageToken": "1"}`))
+		case "/changes/watch":
+			c.watches.Add(1)
+			w.Write([]byte(`{"id": "axis-test", "resourceId": "res"}`))
+		case "/channels/stop":
+			c.stops.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestDriveWatchFollowsSubjectSwitch(t *testing.T) {
+	s := setupTestServer(t)
+	s.driveWebhookURL = "https://axis.example.com/api/drive/webhook"
+	s.driveToken = "secret"
+	s.driveRewatch = make(chan struct{}, 1)
+	var first, second channelStub
+	s.ws = first.workspace(t)
+
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); !done(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.runDriveWatch(ctx)
+		close(stopped)
+	}()
+	waitFor("the first channel", func() bool { return first.watches.Load() == 1 })
+
+	// Switching subjects re-registers at once and stops the old channel with
+	// the credentials that created it.
+	s.wsMu.Lock()
+	s.ws = second.workspace(t)
+	s.wsMu.Unlock()
+	s.requestRewatch()
+	waitFor("the second channel", func() bool { return second.watches.Load() == 1 })
+	waitFor("the first channel to stop", func() bool { return first.stops.Load() == 1 })
+	if second.stops.Load() != 0 {
+		t.Errorf("expected the new subject not to stop the old channel, got %d stops", second.stops.Load())
+	}
+
+	cancel()
+	<-stopped
+	if second.stops.Load() != 1 {
+		t.Errorf("expected the active channel to be stopped on shutdown, got %d stops", second.stops.Load())
+	}
+}
+
+func TestPollSkipsDriveListWhenUnchanged(t *testing.T) {
+	var fileLists, noteLists int32
+	var changed atomic.Bool
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/notes":
+			atomic.AddInt32(&noteLists, 1)
+			w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "Note"}]}`))
+		case "/files":
+			atomic.AddInt32(&fileLists, 1)
+			if strings.Contains(r.URL.Query().Get("q"), "document") {
+				w.Write([]byte(`{"files": [{"id": "doc-1", "name": "Doc"}]}`))
+				return
+			}
+			w.Write([]byte(`{"files": []}`))
+		case "/v1/documents/doc-1":
+			w.Write([]byte(`{"documentId": "doc-1"}`))
+		case "/changes/startPageToken":
+			w.Write([]byte(`{"startPageToken": "10"}`))
+		case "/changes":
+			if r.URL.Query().Get("pageToken") != "10" {
+				t.Errorf("expected the stored token, got %q", r.URL.Query().Get("pageToken"))
+			}
+			if changed.Load() {
+				w.Write([]byte(`{"changes": [{"fileId": "doc-1"}], "newStartPageToken": "10"}`))
+				return
+			}
+			w.Write([]byte(`{"changes": [], "newStartPageToken": "10"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+
+	ctx := context.Background()
+	s.pollRegistry(ctx)
+	listsAfterFull := atomic.LoadInt32(&fileLists)
+	if listsAfterFull == 0 {
+		t.Fatal("expected the first poll to list Drive")
+	}
+
+	s.pollRegistry(ctx)
+	if n := atomic.LoadInt32(&fileLists); n != listsAfterFull {
+		t.Errorf("expected no Drive listing without changes, got %d extra", n-listsAfterFull)
+	}
+	if n := atomic.LoadInt32(&noteLists); n != 2 {
+		t.Errorf("expected Keep to be polled every cycle, got %d lists", n)
+	}
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 2 {
+		t.Errorf("expected cached Drive items to be kept alongside Keep, got %+v", items)
+	}
+
+	changed.Store(true)
+	s.pollRegistry(ctx)
+	if n := atomic.LoadInt32(&fileLists); n == listsAfterFull {
+		t.Error("expected a Drive listing once changes are reported")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/health.go
+Description: Detailed health report. Checks each subsystem independently so one
+failing check never hides the others.
+*/
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthError    = "error"
+)
+
+// healthState remembers the last Google API failure for the health report.
+type healthState struct {
+	mu          sync.Mutex
+	lastErr     string
+	lastErrTime time.Time
+}
+
+func (h *healthState) recordAPIError(err error, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastErr = err.Error()
+	h.lastErrTime = at
+}
+
+func (h *healthState) lastAPIError() (string, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastErr, h.lastErrTime
+}
+
+// HealthCheck is the outcome of one subsystem check.
+type HealthCheck struct {
+	Status string     `json:"status"`
+	Detail string     `json:"detail,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+// HealthReport aggregates the subsystem checks. Status is the worst of them.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// healthReport runs every subsystem check.
+func (s *Server) healthReport() HealthReport {
+	checks := map[string]HealthCheck{
+		"database": s.checkDatabase(),
+		"cache":    s.checkCache(),
+		"clients":  s.checkClients(),
+		"google":   s.checkGoogle(),
+		"notes":    s.checkNoteCache(),
+	}
+
+	report := HealthReport{Status: healthOK, Checks: checks}
+	for _, check := range checks {
+		switch {
+		case check.Status == healthError:
+			report.Status = healthError
+		case check.Status == healthDegraded && report.Status == healthOK:
+			report.Status = healthDegraded
+		}
+	}
+	return report
+}
+
+func (s *Server) checkDatabase() HealthCheck {
+	if err := s.db.Ping(); err != nil {
+		return HealthCheck{Status: healthError, Detail: err.Error()}
+	}
+	if s.dbInMemory {
+		return HealthCheck{Status: healthDegraded, Detail: "in-memory fallback; state is lost on restart"}
+	}
+	return HealthCheck{Status: healthOK}
+}
+
+// checkCache reports the last successful refresh. A refresh older than a few
+// TTLs means the poller has been failing.
+func (s *Server) checkCache() HealthCheck {
+	s.registryCache.mu.RLock()
+	refreshed := s.registryCache.refreshedAt
+	s.registryCache.mu.RUnlock()
+
+	if refreshed.IsZero() {
+		return HealthCheck{Status: healthDegraded, Detail: "no successful refresh yet"}
+	}
+	check := HealthCheck{Status: healthOK, Time: &refreshed}
+	if age := s.now().Sub(refreshed); age > 3*cacheTTL {
+		check.Status = healthDegraded
+		check.Detail = fmt.Sprintf("last refresh %s ago", age.Round(time.Second))
+	}
+	return check
+}
+
+func (s *Server) checkClients() HealthCheck {
+	s.clientsMu.Lock()
+	n, dropped := len(s.clients), s.droppedEvents
+	s.clientsMu.Unlock()
+	return HealthCheck{Status: healthOK, Detail: fmt.Sprintf("%d connected, %d events dropped", n, dropped)}
+}
+
+// checkNoteCache reports how often note detail reads were served from cache.
+func (s *Server) checkNoteCache() HealthCheck {
+	hits, misses := s.noteCacheStats()
+	return HealthCheck{Status: healthOK, Detail: fmt.Sprintf("%d hits, %d misses", hits, misses)}
+}
+
+// checkGoogle reports the last Google API error, unless a refresh has succeeded since.
+func (s *Server) checkGoogle() HealthCheck {
+	msg, at := s.health.lastAPIError()
+	if msg == "" {
+		return HealthCheck{Status: healthOK}
+	}
+	s.registryCache.mu.RLock()
+	refreshed := s.registryCache.refreshedAt
+	s.registryCache.mu.RUnlock()
+
+	check := HealthCheck{Status: healthDegraded, Detail: msg, Time: &at}
+	if refreshed.After(at) {
+		check.Status = healthOK
+	}
+	return check
+}
+
+// handleHealthDetail reports the health of each subsystem. It always answers
+// 200; the per-check statuses carry the verdict.
+func (s *Server) handleHealthDetail(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.healthReport())
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/health_test.go
+Description: Unit tests for the detailed health report.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthDetailReportsEachSubsystem(t *testing.T) {
+	s := setupTestServer(t)
+	s.clients[make(chan SSEMessage, 1)] = newSSEClient()
+	s.health.recordAPIError(errors.New("googleapi: Error 503"), time.Now().Add(-time.Minute))
+	s.registryCache.refreshedAt = time.Now().Add(-2 * time.Minute)
+
+	report := getHealthDetail(t, s)
+	for _, key := range []string{"database", "cache", "clients", "google"} {
+		check, ok := report.Checks[key]
+		if !ok || check.Status == "" {
+			t.Errorf("expected a status for %s, got %+v", key, report.Checks)
+		}
+	}
+	if got := report.Checks["google"]; got.Status != healthDegraded || got.Detail != "googleapi: Error 503" {
+		t.Errorf("expected the last API error to degrade google, got %+v", got)
+	}
+	if got := report.Checks["clients"]; got.Detail != "1 connected, 0 events dropped" {
+		t.Errorf("expected 1 connected client, got %+v", got)
+	}
+	if report.Status != healthDegraded {
+		t.Errorf("expected an overall degraded status, got %q", report.Status)
+	}
+
+	// A failing check is reported without hiding the others.
+	s.db.Close()
+	report = getHealthDetail(t, s)
+	if report.Checks["database"].Status != healthError || report.Status != healthError {
+		t.Errorf("expected a database error, got %+v", report)
+	}
+	if report.Checks["cache"].Status != healthOK {
+		t.Errorf("expected the cache check to be unaffected, got %+v", report.Checks["cache"])
+	}
+}
+
+func getHealthDetail(t *testing.T, s *Server) HealthReport {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	s.handleHealthDetail(rr, httptest.NewRequest("GET", "/api/health/detail", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var report HealthReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/impersonate.go
+Description: Runtime switching of the impersonated Workspace subject. Swaps the
+active workspace Service for one drawn from a per-subject pool and rebuilds the
+registry for the new identity.
+*/
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// ImpersonationResponse reports the subject the server is currently acting as.
+type ImpersonationResponse struct {
+	Subject string `json:"subject"`
+}
+
+// EnableImpersonation allows the acting subject to be switched at runtime using
+// services drawn from pool. subject is the identity the current service acts as,
+// and the one the stored statuses belong to. Switching stays off unless
+// Config.ImpersonationToken is set.
+func (s *Server) EnableImpersonation(pool *workspace.ServicePool, subject string) {
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	s.pool = pool
+	s.subject = subject
+	s.homeSubject = subject
+}
+
+// workspace returns the currently active workspace service.
+func (s *Server) workspace() *workspace.Service {
+	s.wsMu.RLock()
+	defer s.wsMu.RUnlock()
+	return s.ws
+}
+
+// currentUser returns the profile of the identity the server acts for.
+func (s *Server) currentUser() *workspace.User {
+	s.wsMu.RLock()
+	defer s.wsMu.RUnlock()
+	return s.user
+}
+
+// ownsStatuses reports whether the acting subject is the one the stored statuses
+// belong to. While another subject is impersonated its registry must not prune them.
+func (s *Server) ownsStatuses() bool {
+	s.wsMu.RLock()
+	defer s.wsMu.RUnlock()
+	return s.pool == nil || strings.EqualFold(s.subject, s.homeSubject)
+}
+
+// impersonationAuthorized reports whether r carries the configured admin token
+// as a bearer token.
+func (s *Server) impersonationAuthorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.impersonationToken)) == 1
+}
+
+// handleImpersonate reports (GET) or switches (POST ?subject=) the acting subject.
+// Switching is an administrative action: it needs the admin bearer token, MANUAL
+// mode and, when an allowlist is configured, a listed subject. Statuses are
+// kept as they are while another subject is acted as, and pruned again only once
+// the original subject is back.
+func (s *Server) handleImpersonate(w http.ResponseWriter, r *http.Request) {
+	s.wsMu.RLock()
+	pool := s.pool
+	current := s.subject
+	s.wsMu.RUnlock()
+
+	if pool == nil || s.impersonationToken == "" {
+		http.Error(w, "impersonation is not enabled", http.StatusNotFound)
+		return
+	}
+
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if !s.impersonationAuthorized(r) {
+		s.logger.Warn("rejected impersonation request with bad token", "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.writeJSON(w, http.StatusOK, ImpersonationResponse{Subject: current})
+		return
+	}
+
+	if !s.isManualMode() {
+		http.Error(w, "impersonation requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+
+	subject := strings.TrimSpace(r.URL.Query().Get("subject"))
+	if subject == "" {
+		http.Error(w, "missing subject", http.StatusBadRequest)
+		return
+	}
+	if s.impersonationSubjects != nil && !s.impersonationSubjects[strings.ToLower(subject)] {
+		http.Error(w, "subject is not in the impersonation allowlist", http.StatusForbidden)
+		return
+	}
+
+	svc, err := pool.Get(r.Context(), subject)
+	if err != nil {
+		s.logger.Error("failed to build services for subject", "subject", subject, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	svc.SetLogger(s.logger)
+	user, err := svc.GetUser(subject)
+	if err != nil {
+		s.logger.Error("failed to look up impersonated subject", "subject", subject, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.wsMu.Lock()
+	s.ws = svc
+	s.user = user
+	s.subject = subject
+	s.wsMu.Unlock()
+
+	// The registry belongs to the previous identity; drop it so the next read refetches.
+	s.registryCache.mu.Lock()
+	s.registryCache.items = nil
+	s.registryCache.hashes = nil
+	s.registryCache.expiresAt = time.Time{}
+	s.registryCache.loaded = false
+	s.registryCache.mu.Unlock()
+	s.clearNoteCache()
+	s.clearSheetValues()
+	s.clearSnippets()
+	s.setDriveCursor(nil, "")
+	s.requestRewatch()
+
+	s.logger.Info("impersonated subject switched", "from", current, "to", subject)
+	go s.refreshAndBroadcast()
+
+	s.writeJSON(w, http.StatusOK, ImpersonationResponse{Subject: subject})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/impersonate_test.go
+Description: Unit tests for switching the impersonated Workspace subject.
+*/
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"axis/internal/workspace"
+)
+
+func TestImpersonateSwapsUserAndKeepsStatuses(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	ws := newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/admin/directory/v1/users/"):
+			w.Write([]byte(`{"id": "456", "primaryEmail": "other@example.com", "name": {"fullName": "Other User"}}`))
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+	s.ws = ws
+	s.impersonationToken = "admin-secret"
+	s.EnableImpersonation(workspace.NewServicePool(func(ctx context.Context, subject string) (*workspace.Service, error) {
+		return ws, nil
+	}), "admin@example.com")
+	s.statuses["notes/1"] = "Active"
+	s.db.SetStatus("notes/1", "Active")
+	s.setDriveCursor(ws, "stale")
+	s.snippets.entries = map[string]snippetEntry{"doc-1": {modified: "t", snippet: "previous subject"}}
+
+	rr := httptest.NewRecorder()
+	s.handleImpersonate(rr, impersonateRequest("other@example.com", "admin-secret"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if user := s.currentUser(); user.Email != "other@example.com" || user.ID != "456" {
+		t.Errorf("expected the user to follow the subject, got %+v", user)
+	}
+	if cursor := s.driveCursor(ws); cursor != "" {
+		t.Errorf("expected the previous subject's changes cursor to be dropped, got %q", cursor)
+	}
+	s.snippets.mu.Lock()
+	previews := len(s.snippets.entries)
+	s.snippets.mu.Unlock()
+	if previews != 0 {
+		t.Errorf("expected the previous subject's previews to be dropped, got %d", previews)
+	}
+
+	// The other subject's registry has none of our notes; they must not be pruned.
+	if err := s.refreshRegistryCache(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.modeMu.RLock()
+	status := s.statuses["notes/1"]
+	s.modeMu.RUnlock()
+	if stored["notes/1"] != "Active" || status != "Active" {
+		t.Errorf("expected the home subject's status to survive, got db=%v memory=%q", stored, status)
+	}
+}
+
+func impersonateRequest(subject, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate?subject="+subject, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestImpersonateRequiresTokenAndAllowlist(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	built := 0
+	pool := workspace.NewServicePool(func(ctx context.Context, subject string) (*workspace.Service, error) {
+		built++
+		return nil, errors.New("unexpected service build")
+	})
+	s.EnableImpersonation(pool, "admin@example.com")
+
+	// Without a configured token switching is off, even with a pool.
+	rr := httptest.NewRecorder()
+	s.handleImpersonate(rr, impersonateRequest("other@example.com", ""))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 while impersonation is unconfigured, got %v", rr.Code)
+	}
+
+	s.impersonationToken = "admin-secret"
+	s.impersonationSubjects = map[string]bool{"allowed@example.com": true}
+	for _, tc := range []struct {
+		name, subject, token string
+		want                 int
+	}{
+		{"no token", "allowed@example.com", "", http.StatusUnauthorized},
+		{"wrong token", "allowed@example.com", "guess", http.StatusUnauthorized},
+		{"unlisted subject", "other@example.com", "admin-secret", http.StatusForbidden},
+	} {
+		rr := httptest.NewRecorder()
+		s.handleImpersonate(rr, impersonateRequest(tc.subject, tc.token))
+		if rr.Code != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, rr.Code)
+		}
+	}
+	if built != 0 {
+		t.Errorf("expected no services to be built for refused requests, got %d", built)
+	}
+	if s.subject != "admin@example.com" {
+		t.Errorf("expected the subject to stay put, got %q", s.subject)
+	}
+}
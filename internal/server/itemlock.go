@@ -0,0 +1,49 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/itemlock.go
+Description: Per-item locking. Serializes mutations that target the same registry
+item (status changes, deletes) without blocking work on unrelated items.
+*/
+package server
+
+import "sync"
+
+// itemLocks hands out one mutex per item ID. Entries are reference counted and
+// dropped once no caller holds or waits on them. The zero value is ready to use.
+type itemLocks struct {
+	mu    sync.Mutex
+	locks map[string]*itemLock
+}
+
+type itemLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock acquires the mutex for id and returns the function that releases it.
+func (l *itemLocks) lock(id string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*itemLock)
+	}
+	entry, ok := l.locks[id]
+	if !ok {
+		entry = &itemLock{}
+		l.locks[id] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/lifecycle.go
+Description: Stepping an item along the status lifecycle. Advance and regress
+move one status forward or back in the configured order, so a client can bind a
+key to "next" without knowing the vocabulary.
+*/
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// StatusStepResponse reports the outcome of an advance or regress.
+type StatusStepResponse struct {
+	ID      string `json:"id"`
+	From    string `json:"from"`
+	Status  string `json:"status"`
+	Changed bool   `json:"changed"`
+}
+
+// handleStatusAdvance moves ?id= to the next lifecycle status. An item at the
+// last status is left where it is.
+func (s *Server) handleStatusAdvance(w http.ResponseWriter, r *http.Request) {
+	s.stepStatus(w, r, 1)
+}
+
+// handleStatusRegress moves ?id= back to the previous lifecycle status. An item
+// at the first status is left where it is.
+func (s *Server) handleStatusRegress(w http.ResponseWriter, r *http.Request) {
+	s.stepStatus(w, r, -1)
+}
+
+func (s *Server) stepStatus(w http.ResponseWriter, r *http.Request, step int) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	// Serialize with deletes and other status changes of the same item.
+	unlock := s.itemLocks.lock(id)
+	if s.isPendingDelete(id) {
+		unlock()
+		http.Error(w, "item is being deleted", http.StatusConflict)
+		return
+	}
+	s.modeMu.Lock()
+	previous, ok := s.statuses[id]
+	if !ok {
+		s.modeMu.Unlock()
+		unlock()
+		http.Error(w, "item has no status", http.StatusNotFound)
+		return
+	}
+	at := slices.Index(s.lifecycle, previous)
+	if at < 0 {
+		s.modeMu.Unlock()
+		unlock()
+		http.Error(w, fmt.Sprintf("status %s is outside the lifecycle; set a status explicitly", previous), http.StatusConflict)
+		return
+	}
+	status := s.lifecycle[min(max(at+step, 0), len(s.lifecycle)-1)]
+	if status != previous {
+		s.statuses[id] = status
+		s.markDirty(id)
+	}
+	s.modeMu.Unlock()
+	unlock()
+
+	resp := StatusStepResponse{ID: id, From: previous, Status: status, Changed: status != previous}
+	if resp.Changed {
+		s.announceStatus(id, previous, status)
+		s.triggerStateSnapshot()
+		s.broadcastRegistry()
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/lifecycle_test.go
+Description: Unit tests for advancing and regressing items along the status lifecycle.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestStatusAdvanceWalksTheLifecycle(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	s.statuses["notes/1"] = "Pending"
+
+	step := func(handler http.HandlerFunc, target string) StatusStepResponse {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("POST", target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %v: %s", target, rr.Code, rr.Body.String())
+		}
+		var resp StatusStepResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	var seen []string
+	for i := 0; i < 5; i++ {
+		seen = append(seen, step(s.handleStatusAdvance, "/api/status/advance?id=notes/1").Status)
+	}
+	want := []string{"Execute", "Active", "Review", "Complete", "Complete"}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected the sequence %v, got %v", want, seen)
+		}
+	}
+	if resp := step(s.handleStatusAdvance, "/api/status/advance?id=notes/1"); resp.Changed {
+		t.Errorf("expected advancing past Complete to be a no-op, got %+v", resp)
+	}
+
+	resp := step(s.handleStatusRegress, "/api/status/regress?id=notes/1")
+	if resp.From != "Complete" || resp.Status != "Review" || !resp.Changed {
+		t.Errorf("expected a regress from Complete to Review, got %+v", resp)
+	}
+	if s.statuses["notes/1"] != "Review" || !s.dirty["notes/1"] {
+		t.Errorf("expected the stored status to follow and be marked dirty, got %q", s.statuses["notes/1"])
+	}
+}
+
+func TestStatusAdvanceRejections(t *testing.T) {
+	s := setupTestServer(t)
+	s.statuses["notes/blocked"] = "Blocked"
+
+	for _, tc := range []struct {
+		target string
+		want   int
+	}{
+		{"/api/status/advance", http.StatusBadRequest},
+		{"/api/status/advance?id=notes/unknown", http.StatusNotFound},
+		{"/api/status/advance?id=notes/blocked", http.StatusConflict},
+	} {
+		rr := httptest.NewRecorder()
+		s.handleStatusAdvance(rr, httptest.NewRequest("POST", tc.target, nil))
+		if rr.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.target, tc.want, rr.Code)
+		}
+	}
+	if s.statuses["notes/blocked"] != "Blocked" {
+		t.Errorf("expected the side state to be left alone, got %q", s.statuses["notes/blocked"])
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/notecache.go
+Description: Note detail cache. Recently fetched Keep notes are reused by
+/api/notes/detail for a short TTL and dropped as soon as the note is edited,
+deleted, or the impersonated identity changes.
+*/
+package server
+
+import (
+	"sync"
+	"time"
+
+	keep "google.golang.org/api/keep/v1"
+)
+
+// noteDetailCache holds recently fetched notes, keyed by note ID.
+type noteDetailCache struct {
+	mu      sync.Mutex
+	entries map[string]noteDetailEntry
+	hits    int
+	misses  int
+}
+
+type noteDetailEntry struct {
+	note    *keep.Note
+	expires time.Time
+}
+
+// cachedNote returns the cached note for id, counting the lookup as a hit or miss.
+func (s *Server) cachedNote(id string) (*keep.Note, bool) {
+	s.noteDetails.mu.Lock()
+	defer s.noteDetails.mu.Unlock()
+	if e, ok := s.noteDetails.entries[id]; ok && s.now().Before(e.expires) {
+		s.noteDetails.hits++
+		return e.note, true
+	}
+	s.noteDetails.misses++
+	return nil, false
+}
+
+// storeNote caches note under id for the configured TTL, evicting expired entries.
+func (s *Server) storeNote(id string, note *keep.Note) {
+	now := s.now()
+
+	s.noteDetails.mu.Lock()
+	defer s.noteDetails.mu.Unlock()
+	if s.noteDetails.entries == nil {
+		s.noteDetails.entries = make(map[string]noteDetailEntry)
+	}
+	for k, e := range s.noteDetails.entries {
+		if !now.Before(e.expires) {
+			delete(s.noteDetails.entries, k)
+		}
+	}
+	s.noteDetails.entries[id] = noteDetailEntry{note: note, expires: now.Add(s.noteCacheTTL)}
+}
+
+// forgetNote drops the cached detail for each id.
+func (s *Server) forgetNote(ids ...string) {
+	s.noteDetails.mu.Lock()
+	defer s.noteDetails.mu.Unlock()
+	for _, id := range ids {
+		delete(s.noteDetails.entries, id)
+	}
+}
+
+// clearNoteCache drops every cached detail, keeping the hit and miss counts.
+func (s *Server) clearNoteCache() {
+	s.noteDetails.mu.Lock()
+	s.noteDetails.entries = nil
+	s.noteDetails.mu.Unlock()
+}
+
+// noteCacheStats returns the hits and misses counted since startup.
+func (s *Server) noteCacheStats() (hits, misses int) {
+	s.noteDetails.mu.Lock()
+	defer s.noteDetails.mu.Unlock()
+	return s.noteDetails.hits, s.noteDetails.misses
+}
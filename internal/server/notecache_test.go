@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/notecache_test.go
+Description: Unit tests for the note detail cache.
+*/
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNoteDetailServedFromCache(t *testing.T) {
+	var fetches atomic.Int32
+	clock := newFakeClock()
+	s := setupTestServer(t)
+	s.clock = clock
+	s.noteCacheTTL = time.Minute
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes/1" {
+			fetches.Add(1)
+		}
+		w.Write([]byte(`{"name": "notes/1", "title": "One"}`))
+	}))
+
+	get := func() {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		s.handleNoteDetail(rr, httptest.NewRequest("GET", "/api/notes/detail?id=notes/1", nil))
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"title":"One"`) {
+			t.Fatalf("expected the note, got %v: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	get()
+	get()
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected the second read within the TTL to be cached, got %d fetches", n)
+	}
+	if hits, misses := s.noteCacheStats(); hits != 1 || misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+	if got := s.healthReport().Checks["notes"].Detail; got != "1 hits, 1 misses" {
+		t.Errorf("expected the stats in the health report, got %q", got)
+	}
+
+	clock.Advance(time.Minute)
+	get()
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected an expired entry to be fetched again, got %d fetches", n)
+	}
+
+	s.markPendingDelete("notes/1")
+	get()
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("expected a delete to drop the cached note, got %d fetches", n)
+	}
+}
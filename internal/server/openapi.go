@@ -0,0 +1,251 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/openapi.go
+Description: OpenAPI 3 description of the stable /api routes, served at
+/api/openapi.json. Routes are listed by hand; schemas are derived from the Go
+response types so they cannot drift from what the handlers encode.
+*/
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// apiParam is a query parameter accepted by an operation.
+type apiParam struct {
+	name        string
+	description string
+	required    bool
+}
+
+// apiOperation describes one method on one path. body and response are sample
+// values whose types describe the JSON payloads; nil means none.
+type apiOperation struct {
+	path        string
+	method      string
+	summary     string
+	params      []apiParam
+	body        any
+	response    any
+	contentType string // response media type; empty means application/json
+}
+
+var idParam = apiParam{name: "id", description: "Item ID", required: true}
+
+// Registry filters accepted by /api/registry and its CSV export.
+var (
+	typeFilterParam  = apiParam{name: "type", description: "Only items of this type"}
+	queryFilterParam = apiParam{name: "q", description: "Only items whose title or snippet contains this text"}
+)
+
+// apiOperations lists the stable endpoints. Keep it in step with Start().
+var apiOperations = []apiOperation{
+	{path: "/api/registry", method: http.MethodGet, summary: "Enriched registry of Workspace items",
+		params: []apiParam{
+			{name: "fields", description: "Comma-separated RegistryItem fields to return"},
+			{name: "refresh", description: "Force an upstream refresh (MANUAL mode); inside the minimum interval the cache is served with X-Refresh-Throttled"},
+			typeFilterParam, queryFilterParam,
+		},
+		response: []workspace.RegistryItem{}},
+	{path: "/api/registry/export.ndjson", method: http.MethodGet, summary: "Registry as newline-delimited JSON",
+		response: workspace.RegistryItem{}, contentType: "application/x-ndjson"},
+	{path: "/api/registry/export.csv", method: http.MethodGet, summary: "Registry as CSV (id, type, title, status, snippet)",
+		params: []apiParam{typeFilterParam, queryFilterParam}, contentType: "text/csv"},
+	{path: "/api/registry/delete", method: http.MethodPost, summary: "Delete any registry item (MANUAL mode)",
+		params: []apiParam{idParam, {name: "type", description: "Item type", required: true}}},
+	{path: "/api/registry/protect", method: http.MethodPost, summary: "Protect an item from deletion",
+		params: []apiParam{idParam}, response: ProtectResponse{}},
+	{path: "/api/registry/unprotect", method: http.MethodPost, summary: "Lift an item's delete protection",
+		params: []apiParam{idParam}, response: ProtectResponse{}},
+	{path: "/api/mode", method: http.MethodGet, summary: "Current operating mode",
+		response: ModeResponse{}},
+	{path: "/api/mode", method: http.MethodPost, summary: "Set the operating mode",
+		params:   []apiParam{{name: "set", description: "AUTO or MANUAL", required: true}},
+		response: ModeResponse{}},
+	{path: "/api/mode/pause", method: http.MethodPost, summary: "Pause AUTO refresh", response: ModeResponse{}},
+	{path: "/api/mode/resume", method: http.MethodPost, summary: "Resume AUTO refresh", response: ModeResponse{}},
+	{path: "/api/mode/countdown", method: http.MethodGet, summary: "Time until the next AUTO refresh",
+		response: CountdownResponse{}},
+	{path: "/api/user", method: http.MethodGet, summary: "Signed-in user profile", response: UserResponse{}},
+	{path: "/api/status", method: http.MethodPost, summary: "Set an item's status",
+		params: []apiParam{
+			idParam,
+			{name: "status", description: "New status", required: true},
+			{name: "annotation", description: "Replaces the annotation when present"},
+			{name: "sync", description: "Persist before responding"},
+		}},
+	{path: "/api/status/summary", method: http.MethodGet, summary: "Counts by status and item type",
+		response: StatusSummaryResponse{}},
+	{path: "/api/status/reset", method: http.MethodPost, summary: "Clear every status (MANUAL mode)",
+		params:   []apiParam{{name: "confirm", description: "Must be true", required: true}},
+		response: StatusResetResponse{}},
+	{path: "/api/status/advance", method: http.MethodPost, summary: "Move an item to the next lifecycle status",
+		params: []apiParam{idParam}, response: StatusStepResponse{}},
+	{path: "/api/status/regress", method: http.MethodPost, summary: "Move an item back to the previous lifecycle status",
+		params: []apiParam{idParam}, response: StatusStepResponse{}},
+	{path: "/api/status/transition", method: http.MethodPost, summary: "Move every item from one status to another",
+		body: StatusTransitionRequest{}, response: StatusTransitionResponse{}},
+	{path: "/api/state/flush", method: http.MethodPost, summary: "Persist pending state now", response: FlushResponse{}},
+	{path: "/api/state/vacuum", method: http.MethodPost, summary: "Compact the database (MANUAL mode)",
+		response: VacuumResponse{}},
+	{path: "/api/health/detail", method: http.MethodGet, summary: "Per-subsystem health report",
+		response: HealthReport{}},
+	{path: "/api/notes/detail", method: http.MethodGet, summary: "Keep note detail", params: []apiParam{idParam}},
+	{path: "/api/notes/search", method: http.MethodGet, summary: "Keep notes matching a query",
+		params: []apiParam{
+			{name: "q", description: "Terms that must all appear in the title or content", required: true},
+			{name: "includeBody", description: "Include each note's content"},
+			{name: "limit", description: "Page size; 0 returns every match"},
+			{name: "offset", description: "Matches to skip"},
+		},
+		response: []NoteSearchResult{}},
+	{path: "/api/notes/delete", method: http.MethodPost, summary: "Delete a Keep note (MANUAL mode)",
+		params: []apiParam{idParam, {name: "force", description: "Allow deleting a shared note"}}},
+	{path: "/api/notes/batch-delete", method: http.MethodPost, summary: "Delete many Keep notes (MANUAL mode)",
+		params: []apiParam{{name: "force", description: "Allow deleting shared notes"}},
+		body:   []string{}, response: []BatchDeleteResult{}},
+	{path: "/api/notes/content", method: http.MethodPut, summary: "Replace a Keep note's text (MANUAL mode); the note gets a new ID",
+		params: []apiParam{idParam, {name: "force", description: "Allow editing shared notes, dropping their collaborators"}},
+		body:   NoteContentRequest{}},
+	{path: "/api/notes/content-batch", method: http.MethodPost, summary: "Content and status of many notes",
+		body: []string{}, response: map[string]NoteContentResult{}},
+	{path: "/api/docs/detail", method: http.MethodGet, summary: "Google Doc text",
+		params: []apiParam{idParam, {name: "maxBytes", description: "Cap the extracted text"}}},
+	{path: "/api/sheets/detail", method: http.MethodGet, summary: "Google Sheet values",
+		params: []apiParam{idParam, {name: "render", description: "FORMATTED_VALUE, UNFORMATTED_VALUE or FORMULA"}}},
+	{path: "/api/sheets/values/page", method: http.MethodGet, summary: "One page of rows from a sheet range",
+		params: []apiParam{
+			idParam,
+			{name: "range", description: "A1 range; defaults to A:Z"},
+			{name: "page", description: "1-based page number"},
+			{name: "size", description: "Rows per page, at most 1000"},
+		},
+		response: SheetPageResponse{}},
+	{path: "/api/sheets/meta", method: http.MethodGet, summary: "Tabs, named ranges and charts of a sheet",
+		params: []apiParam{idParam}, response: SheetMetaResponse{}},
+	{path: "/api/forms/detail", method: http.MethodGet, summary: "Google Form questions", params: []apiParam{idParam}},
+	{path: "/api/gmail/detail", method: http.MethodGet, summary: "Gmail thread text", params: []apiParam{idParam}},
+	{path: "/api/events", method: http.MethodGet, summary: "Server-sent event stream", contentType: "text/event-stream"},
+	{path: "/api/events/clients", method: http.MethodGet, summary: "Connected SSE clients",
+		response: []SSEClientInfo{}},
+	{path: "/api/openapi.json", method: http.MethodGet, summary: "This document"},
+}
+
+// buildOpenAPISpec renders apiOperations as an OpenAPI 3 document.
+func buildOpenAPISpec() map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]map[string]any)
+	for _, op := range apiOperations {
+		operation := map[string]any{"summary": op.summary}
+		if len(op.params) > 0 {
+			params := make([]map[string]any, len(op.params))
+			for i, p := range op.params {
+				params[i] = map[string]any{
+					"name":        p.name,
+					"in":          "query",
+					"description": p.description,
+					"required":    p.required,
+					"schema":      map[string]any{"type": "string"},
+				}
+			}
+			operation["parameters"] = params
+		}
+		if op.body != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(op.body), schemas)},
+				},
+			}
+		}
+		ok := map[string]any{"description": "OK"}
+		if op.response != nil || op.contentType != "" {
+			media := map[string]any{}
+			if op.response != nil {
+				media["schema"] = schemaOf(reflect.TypeOf(op.response), schemas)
+			}
+			contentType := op.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			ok["content"] = map[string]any{contentType: media}
+		}
+		operation["responses"] = map[string]any{"200": ok}
+
+		if paths[op.path] == nil {
+			paths[op.path] = make(map[string]any)
+		}
+		paths[op.path][strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Axis API",
+			"version": "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema for t. Named structs are registered in schemas and
+// referenced by name.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		props := make(map[string]any)
+		schemas[t.Name()] = map[string]any{"type": "object", "properties": props}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			props[name] = schemaOf(field.Type, schemas)
+		}
+		return ref
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
+
+// handleOpenAPI serves the OpenAPI description of the API.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, buildOpenAPISpec())
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/openapi_test.go
+Description: Unit tests for the OpenAPI description endpoint.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleOpenAPI(t *testing.T) {
+	s := setupTestServer(t)
+	rr := httptest.NewRecorder()
+	s.handleOpenAPI(rr, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+	raw := rr.Body.String()
+
+	var spec struct {
+		OpenAPI    string                    `json:"openapi"`
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("expected an OpenAPI 3 document, got %q", spec.OpenAPI)
+	}
+	for _, path := range []string{"/api/registry", "/api/mode", "/api/user", "/api/status", "/api/events"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("expected path %s in the spec", path)
+		}
+	}
+	if _, ok := spec.Paths["/api/mode"]["post"]; !ok {
+		t.Error("expected both methods of /api/mode to be described")
+	}
+	if _, ok := spec.Components.Schemas["RegistryItem"].Properties["status"]; !ok {
+		t.Errorf("expected RegistryItem schema to carry its json field names, got %+v", spec.Components.Schemas["RegistryItem"])
+	}
+
+	// Every reference must resolve to a registered schema.
+	for _, part := range strings.Split(raw, `"#/components/schemas/`)[1:] {
+		name, _, _ := strings.Cut(part, `"`)
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("dangling schema reference %q", name)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/persist.go
+Description: State persistence strategy. Tracks dirty statuses and the mode in
+memory and writes them to SQLite either immediately (sync) or in periodic
+batches from a background flusher (async).
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+const (
+	persistModeSync  = "sync"
+	persistModeAsync = "async"
+)
+
+func validPersistMode(mode string) bool {
+	return mode == persistModeSync || mode == persistModeAsync
+}
+
+// markDirty records that the given status IDs need to be written. Callers must hold modeMu.
+func (s *Server) markDirty(ids ...string) {
+	if s.dirty == nil {
+		s.dirty = make(map[string]bool)
+	}
+	for _, id := range ids {
+		s.dirty[id] = true
+	}
+}
+
+// markModeDirty records that the mode needs to be written. Callers must hold modeMu.
+func (s *Server) markModeDirty() {
+	s.modeDirty = true
+}
+
+// triggerStateSnapshot persists pending state immediately in sync mode. In async
+// mode the write is deferred to the periodic flusher.
+func (s *Server) triggerStateSnapshot() {
+	if s.persistMode == persistModeSync {
+		s.flushState()
+	}
+}
+
+// flushState writes the mode (if changed) and all dirty statuses to the database,
+// returning the number of entries written. Failed writes stay dirty for the next flush.
+func (s *Server) flushState() int {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	s.modeMu.Lock()
+	mode := s.mode
+	writeMode := s.modeDirty
+	s.modeDirty = false
+	pending := make(map[string][2]string, len(s.dirty))
+	for id := range s.dirty {
+		if status, ok := s.statuses[id]; ok {
+			pending[id] = [2]string{status, s.annotations[id]}
+		}
+	}
+	s.dirty = nil
+	s.modeMu.Unlock()
+
+	// Write in a stable order so repeated flushes of the same state are identical.
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	written := 0
+	var failed []string
+
+	if writeMode {
+		if err := s.db.SetMode(mode); err != nil {
+			s.logger.Error("failed to persist mode", "error", err)
+			s.modeMu.Lock()
+			s.markModeDirty()
+			s.modeMu.Unlock()
+		} else {
+			written++
+		}
+	}
+
+	for _, id := range ids {
+		if err := s.db.SetStatusAnnotation(id, pending[id][0], pending[id][1]); err != nil {
+			s.logger.Error("failed to persist status", "id", id, "error", err)
+			failed = append(failed, id)
+			continue
+		}
+		written++
+	}
+
+	if len(failed) > 0 {
+		s.modeMu.Lock()
+		s.markDirty(failed...)
+		s.modeMu.Unlock()
+	}
+
+	return written
+}
+
+// setStatusNow writes the status and annotation of id to the database
+// immediately, regardless of the persist mode, and clears its pending dirty flag.
+// Like flushState it holds persistMu so the write never overlaps a flush or vacuum.
+func (s *Server) setStatusNow(id string) error {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	s.modeMu.Lock()
+	status, ok := s.statuses[id]
+	annotation := s.annotations[id]
+	delete(s.dirty, id)
+	s.modeMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if err := s.db.SetStatusAnnotation(id, status, annotation); err != nil {
+		s.modeMu.Lock()
+		s.markDirty(id)
+		s.modeMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// snapshotState synchronously writes the mode and every in-memory status,
+// regardless of dirty tracking, returning the number of entries written.
+func (s *Server) snapshotState() int {
+	s.modeMu.Lock()
+	for id := range s.statuses {
+		s.markDirty(id)
+	}
+	s.markModeDirty()
+	s.modeMu.Unlock()
+	return s.flushState()
+}
+
+// FlushResponse reports how many entries a forced flush wrote.
+type FlushResponse struct {
+	Written int `json:"written"`
+}
+
+// handleStateFlush forces a synchronous write of all state, e.g. before a backup.
+func (s *Server) handleStateFlush(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "state flush requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+
+	written := s.snapshotState()
+	s.logger.Info("forced state flush", "entries", written)
+
+	s.writeJSON(w, http.StatusOK, FlushResponse{Written: written})
+}
+
+// exportState renders the mode, statuses, and annotations as indented JSON. Map keys are
+// emitted in sorted order, so the same state always yields the same bytes.
+func (s *Server) exportState() ([]byte, error) {
+	s.modeMu.RLock()
+	ps := persistentState{
+		Mode:     s.mode,
+		Statuses: make(map[string]string, len(s.statuses)),
+	}
+	for id, status := range s.statuses {
+		ps.Statuses[id] = status
+	}
+	for id, annotation := range s.annotations {
+		if ps.Annotations == nil {
+			ps.Annotations = make(map[string]string, len(s.annotations))
+		}
+		ps.Annotations[id] = annotation
+	}
+	s.modeMu.RUnlock()
+
+	data, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// handleStateExport returns the current state in the legacy state file format,
+// suitable for checking into version control.
+func (s *Server) handleStateExport(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	data, err := s.exportState()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// runStateFlusher periodically writes dirty state in async mode and performs a
+// final flush when the context is canceled.
+func (s *Server) runStateFlusher(ctx context.Context) {
+	interval := s.persistEvery
+	if interval <= 0 {
+		interval = persistInterval
+	}
+	ticker := s.newTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if n := s.flushState(); n > 0 {
+				s.logger.Debug("state flushed", "entries", n)
+			}
+		case <-ctx.Done():
+			n := s.flushState()
+			s.logger.Info("final state flush", "entries", n)
+			return
+		}
+	}
+}
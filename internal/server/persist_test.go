@@ -0,0 +1,413 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/persist_test.go
+Description: Unit tests for the sync and async state persistence strategies.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestAsyncPersistDefersWrites(t *testing.T) {
+	s := setupTestServer(t)
+	s.persistMode = persistModeAsync
+	s.persistEvery = 50 * time.Millisecond
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}}
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Active", nil))
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := statuses["item-1"]; ok {
+		t.Fatal("expected async mode to defer the write")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runStateFlusher(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		statuses, err = s.db.GetStatuses()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statuses["item-1"] == "Active" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected the periodic flusher to persist the status")
+}
+
+func TestSyncPersistWritesThrough(t *testing.T) {
+	s := setupTestServer(t)
+	s.persistMode = persistModeSync
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}}
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Blocked", nil))
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["item-1"] != "Blocked" {
+		t.Errorf("expected write-through status Blocked, got %q", statuses["item-1"])
+	}
+}
+
+func TestFlusherFlushesOnShutdown(t *testing.T) {
+	s := setupTestServer(t)
+	s.persistEvery = time.Hour
+
+	s.modeMu.Lock()
+	s.statuses["item-1"] = "Review"
+	s.markDirty("item-1")
+	s.modeMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.runStateFlusher(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["item-1"] != "Review" {
+		t.Errorf("expected shutdown flush to persist Review, got %q", statuses["item-1"])
+	}
+}
+
+func TestHandleStateFlush(t *testing.T) {
+	s := setupTestServer(t)
+	s.statuses["notes/1"] = "Active"
+	s.statuses["notes/2"] = "Review"
+
+	rr := httptest.NewRecorder()
+	s.handleStateFlush(rr, httptest.NewRequest("POST", "/api/state/flush", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 outside MANUAL mode, got %v", rr.Code)
+	}
+
+	s.mode = "MANUAL"
+	rr = httptest.NewRecorder()
+	s.handleStateFlush(rr, httptest.NewRequest("POST", "/api/state/flush", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+
+	var resp FlushResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	// Two statuses plus the mode.
+	if resp.Written != 3 {
+		t.Errorf("expected 3 entries written, got %d", resp.Written)
+	}
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["notes/1"] != "Active" || statuses["notes/2"] != "Review" {
+		t.Errorf("expected flushed statuses in DB, got %v", statuses)
+	}
+	mode, err := s.db.GetMode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != "MANUAL" {
+		t.Errorf("expected flushed mode MANUAL, got %s", mode)
+	}
+}
+
+func TestStateExportIsDeterministic(t *testing.T) {
+	export := func(ids []string) []byte {
+		s := setupTestServer(t)
+		s.mode = "MANUAL"
+		for i, id := range ids {
+			s.statuses[id] = []string{"Pending", "Active", "Review"}[i%3]
+		}
+		rr := httptest.NewRecorder()
+		s.handleStateExport(rr, httptest.NewRequest("GET", "/api/state/export", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %v", rr.Code)
+		}
+		return rr.Body.Bytes()
+	}
+
+	ids := []string{"notes/c", "notes/a", "doc-9", "notes/b", "sheet-2", "notes/e", "notes/d"}
+	first := export(ids)
+	for i := 0; i < 5; i++ {
+		if again := export(ids); !bytes.Equal(first, again) {
+			t.Fatalf("expected byte-identical exports, got:\n%s\nvs\n%s", first, again)
+		}
+	}
+
+	var ps persistentState
+	if err := json.Unmarshal(first, &ps); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Mode != "MANUAL" || len(ps.Statuses) != len(ids) {
+		t.Errorf("expected the full state in the export, got %+v", ps)
+	}
+}
+
+func TestSyncStatusWriteIsImmediate(t *testing.T) {
+	s := setupTestServer(t)
+	s.persistMode = persistModeAsync
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}, {ID: "item-2", Title: "Other"}}
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Blocked&sync=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=item-2&status=Active", nil))
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["item-1"] != "Blocked" {
+		t.Errorf("expected sync=true write to be in the database, got %q", statuses["item-1"])
+	}
+	if _, ok := statuses["item-2"]; ok {
+		t.Error("expected the plain write to stay deferred in async mode")
+	}
+	if s.dirty["item-1"] {
+		t.Error("expected the synced item not to remain dirty")
+	}
+}
+
+func TestFailedSyncStatusWriteIsNotAnnounced(t *testing.T) {
+	var delivered atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer receiver.Close()
+
+	s := setupTestServer(t)
+	s.webhook = newWebhookNotifier(receiver.URL, s.logger)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}}
+	s.statuses["item-1"] = "Pending"
+	events := make(chan SSEMessage, 4)
+	s.clients[events] = newSSEClient()
+	s.db.Close()
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Active&sync=true", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the sync write fails, got %v", rr.Code)
+	}
+
+	select {
+	case msg := <-events:
+		t.Errorf("expected no event for an unpersisted status, got %q", msg.Event)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if n := delivered.Load(); n != 0 {
+		t.Errorf("expected no webhook for an unpersisted status, got %d deliveries", n)
+	}
+}
+
+func TestStatusAnnotationRoundTrip(t *testing.T) {
+	s := setupTestServer(t)
+	s.persistMode = persistModeSync
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "Blocked Note"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=notes/1&status=Blocked&annotation=waiting+on+legal", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	// Reload from the database to prove the annotation persisted.
+	s.annotations = nil
+	s.loadState()
+	if got := s.annotations["notes/1"]; got != "waiting on legal" {
+		t.Fatalf("expected the annotation to persist, got %q", got)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleRegistry(rr, httptest.NewRequest("GET", "/api/registry", nil))
+	var items []workspace.RegistryItem
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Annotation != "waiting on legal" {
+		t.Fatalf("expected the annotation in the registry response, got %+v", items)
+	}
+
+	data, err := s.exportState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"notes/1": "waiting on legal"`)) {
+		t.Errorf("expected the annotation in the export, got %s", data)
+	}
+
+	// Omitting the parameter keeps the annotation; an empty one clears it.
+	s.handleStatus(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/status?id=notes/1&status=Active", nil))
+	if got := s.annotations["notes/1"]; got != "waiting on legal" {
+		t.Errorf("expected the annotation to survive a plain status change, got %q", got)
+	}
+	s.handleStatus(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/status?id=notes/1&status=Active&annotation=", nil))
+	if annotations, _ := s.db.GetAnnotations(); len(annotations) != 0 {
+		t.Errorf("expected the annotation to be cleared, got %v", annotations)
+	}
+}
+
+func TestNewServerUsesDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"mode": "MANUAL", "statuses": {"notes/1": "Blocked"}}`
+	if err := os.WriteFile(filepath.Join(dir, stateFileName), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(nil, nil, Config{DataDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+
+	if _, err := os.Stat(filepath.Join(dir, dbFileName)); err != nil {
+		t.Errorf("expected the database in the data dir: %v", err)
+	}
+	if _, err := os.Stat(dbFileName); err == nil {
+		t.Errorf("expected no database in the working directory")
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, stateFileName+".*.bak")); len(backups) != 1 {
+		t.Errorf("expected the legacy state file to be backed up in place, got %v", backups)
+	}
+	if s.mode != "MANUAL" || s.statuses["notes/1"] != "Blocked" {
+		t.Errorf("expected migrated state, got mode %q statuses %v", s.mode, s.statuses)
+	}
+}
+
+func TestLegacyMigrationIsIdempotent(t *testing.T) {
+	s := setupTestServer(t)
+	s.dataDir = t.TempDir()
+	clk := newFakeClock()
+	s.clock = clk
+
+	// Newer database state must survive the import.
+	if err := s.db.SetStatus("notes/1", "Complete"); err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte(`{"mode": "MANUAL", "statuses": {"notes/1": "Blocked", "notes/2": "Review"}}`)
+	if err := os.WriteFile(s.statePath(), legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.loadState()
+	if s.statuses["notes/1"] != "Complete" || s.statuses["notes/2"] != "Review" {
+		t.Fatalf("expected only missing statuses imported, got %v", s.statuses)
+	}
+	if s.mode != "MANUAL" {
+		t.Errorf("expected the legacy mode imported, got %q", s.mode)
+	}
+
+	// A leftover or restored file must not be imported again.
+	if err := s.db.SetStatus("notes/2", "Active"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.statePath(), legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(time.Second)
+	s.loadState()
+	if s.statuses["notes/2"] != "Active" {
+		t.Errorf("expected the second run to be a no-op, got %v", s.statuses)
+	}
+	if _, err := os.Stat(s.statePath()); err != nil {
+		t.Errorf("expected the already-migrated file to be left alone: %v", err)
+	}
+	if backups, _ := filepath.Glob(s.statePath() + ".*.bak"); len(backups) != 1 {
+		t.Errorf("expected exactly one backup, got %v", backups)
+	}
+}
+
+func TestDefaultModeAppliesOnlyWithoutStoredMode(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServer(nil, nil, Config{DataDir: dir, DefaultMode: "MANUAL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.mode != "MANUAL" {
+		t.Errorf("expected a fresh install to start in MANUAL, got %q", s.mode)
+	}
+	if err := s.db.SetMode("AUTO"); err != nil {
+		t.Fatal(err)
+	}
+	s.db.Close()
+
+	s, err = NewServer(nil, nil, Config{DataDir: dir, DefaultMode: "MANUAL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+	if s.mode != "AUTO" {
+		t.Errorf("expected the stored mode to win, got %q", s.mode)
+	}
+}
+
+func TestDatabaseOpenFailure(t *testing.T) {
+	// A directory where the database file belongs cannot be opened as SQLite.
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, dbFileName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewServer(nil, nil, Config{DataDir: dir}); err == nil {
+		t.Fatal("expected an error without a fallback")
+	}
+
+	s, err := NewServer(nil, nil, Config{DataDir: dir, DBFallback: dbFallbackMemory})
+	if err != nil {
+		t.Fatalf("expected the memory fallback to start, got %v", err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+
+	if err := s.db.SetStatusAnnotation("notes/1", "Active", ""); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses["notes/1"] != "Active" {
+		t.Errorf("expected the status in the in-memory database, got %v", statuses)
+	}
+	if check := s.checkDatabase(); check.Status != healthDegraded {
+		t.Errorf("expected the database check to report the fallback, got %+v", check)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/projection.go
+Description: Field projection for registry responses. Lets lightweight clients
+request only the RegistryItem fields they render via ?fields=.
+*/
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"axis/internal/workspace"
+)
+
+// registryFields holds the JSON names of every RegistryItem field.
+var registryFields = jsonFieldNames(reflect.TypeOf(workspace.RegistryItem{}))
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseFields reads a comma-separated ?fields= list, rejecting unknown names.
+// An empty list means no projection.
+func parseFields(raw string) ([]string, error) {
+	var fields []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !registryFields[part] {
+			return nil, fmt.Errorf("unknown field %q", part)
+		}
+		fields = append(fields, part)
+	}
+	return fields, nil
+}
+
+// projectItems renders each item as an object holding only fields. Fields that
+// are omitted when empty stay omitted.
+func projectItems(items []workspace.RegistryItem, fields []string) ([]map[string]json.RawMessage, error) {
+	out := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]json.RawMessage
+		if err := json.Unmarshal(data, &full); err != nil {
+			return nil, err
+		}
+		out[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if v, ok := full[field]; ok {
+				out[i][field] = v
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/projection_test.go
+Description: Unit tests for ?fields= projection on the registry endpoint.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestRegistryFieldProjection(t *testing.T) {
+	s := setupTestServer(t)
+	s.statuses["notes/1"] = "Active"
+	s.registryCache.items = []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep", Title: "One", Snippet: "Google Keep Note", ModifiedTime: "2026-01-01T00:00:00Z"},
+	}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	rr := httptest.NewRecorder()
+	s.handleRegistry(rr, httptest.NewRequest("GET", "/api/registry?fields=id,title,status", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var items []map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	var keys []string
+	for k := range items[0] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"id", "status", "title"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected keys %v, got %v", want, keys)
+	}
+	if items[0]["status"] != "Active" {
+		t.Errorf("expected the enriched status, got %v", items[0]["status"])
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleRegistry(rr, httptest.NewRequest("GET", "/api/registry?fields=id,color", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown field, got %v", rr.Code)
+	}
+}
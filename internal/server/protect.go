@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/protect.go
+Description: Delete protection. Items pinned as protected are refused by every
+delete handler regardless of mode until they are unprotected again.
+*/
+package server
+
+import (
+	"net/http"
+)
+
+// ProtectResponse reports an item's protection after a protect or unprotect.
+type ProtectResponse struct {
+	ID        string `json:"id"`
+	Protected bool   `json:"protected"`
+}
+
+// isProtected reports whether id may not be deleted.
+func (s *Server) isProtected(id string) bool {
+	s.modeMu.RLock()
+	defer s.modeMu.RUnlock()
+	return s.protected[id]
+}
+
+// setProtected persists the protection for id before updating memory, so a
+// protection the caller was told about survives a restart.
+func (s *Server) setProtected(id string, protected bool) error {
+	if err := s.db.SetProtected(id, protected); err != nil {
+		return err
+	}
+	s.modeMu.Lock()
+	if protected {
+		if s.protected == nil {
+			s.protected = make(map[string]bool)
+		}
+		s.protected[id] = true
+	} else {
+		delete(s.protected, id)
+	}
+	s.modeMu.Unlock()
+	return nil
+}
+
+// handleProtect pins ?id= as protected from deletion.
+func (s *Server) handleProtect(w http.ResponseWriter, r *http.Request) {
+	s.updateProtection(w, r, true)
+}
+
+// handleUnprotect lifts the delete protection from ?id=. Like a status reset,
+// it re-exposes items to destructive actions and so requires MANUAL mode.
+func (s *Server) handleUnprotect(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "unprotect requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+	s.updateProtection(w, r, false)
+}
+
+func (s *Server) updateProtection(w http.ResponseWriter, r *http.Request, protected bool) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	// Serialize with deletes of the same item so a delete in flight cannot slip past.
+	unlock := s.itemLocks.lock(id)
+	err := s.setProtected(id, protected)
+	unlock()
+	if err != nil {
+		s.logger.Error("failed to persist protection", "id", id, "error", err)
+		http.Error(w, "failed to persist protection", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("item protection changed", "id", id, "protected", protected)
+
+	s.broadcastRegistry()
+	s.writeJSON(w, http.StatusOK, ProtectResponse{ID: id, Protected: protected})
+}
@@ -11,13 +11,25 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"axis/internal/database"
@@ -31,23 +43,272 @@ const (
 	persistInterval  = 10 * time.Second
 	pollInterval     = 1 * time.Second
 	autoRefreshTicks = 60
+
+	defaultMaxSSEClients  = 100
+	defaultSSERetry       = 3 * time.Second
+	defaultSSEBuffer      = 10
+	defaultMaxBodyBytes   = 1 << 20
+	defaultFetchTimeout   = 30 * time.Second
+	defaultRefreshRetries = 3
+	defaultNoteCacheTTL   = 30 * time.Second
+	defaultCacheJitter    = 0.1
+	defaultMinRefresh     = 2 * time.Second
+
+	// maxBatchContent caps the ids accepted by /api/notes/content-batch.
+	maxBatchContent = 100
+	// batchContentWorkers bounds concurrent note fetches within a batch.
+	batchContentWorkers = 5
+	// noteFetchTimeout bounds each note fetch within a batch.
+	noteFetchTimeout = 10 * time.Second
+
+	// dbFallbackMemory runs on an in-memory database when the file cannot be opened.
+	dbFallbackMemory = "memory"
+
+	defaultItemStatus = "Pending"
+	maxAnnotationLen  = 500
 )
 
-var allowedStatuses = map[string]bool{
-	"Pending":  true,
-	"Execute":  true,
-	"Active":   true,
-	"Blocked":  true,
-	"Review":   true,
-	"Complete": true,
-	"Error":    true,
+// itemTypes are all registry item types.
+var itemTypes = map[string]bool{
+	"keep":  true,
+	"doc":   true,
+	"sheet": true,
+	"form":  true,
+	"gmail": true,
+}
+
+// driveItemTypes are the registry types listed from Drive.
+var driveItemTypes = map[string]bool{
+	"doc":   true,
+	"sheet": true,
+	"form":  true,
+}
+
+// defaultStatuses is the lifecycle used when Config.Statuses is empty.
+var defaultStatuses = []string{"Pending", "Execute", "Active", "Blocked", "Review", "Complete", "Error"}
+
+// defaultLifecycle is the order /api/status/advance walks with the built-in
+// statuses. Blocked and Error are side states outside it.
+var defaultLifecycle = []string{"Pending", "Execute", "Active", "Review", "Complete"}
+
+// Config carries operator-tunable settings for the server.
+type Config struct {
+	// DefaultStatus is assigned to newly discovered Keep notes. Empty means "Pending".
+	DefaultStatus string
+	// WebhookURL receives a POST for every status change. Empty disables delivery.
+	WebhookURL string
+	// PersistMode selects write-through ("sync") or batched ("async") persistence. Empty means async.
+	PersistMode string
+	// DriveWebhookURL is the public address of /api/drive/webhook. When set, Drive
+	// change notifications trigger immediate refreshes in addition to polling.
+	DriveWebhookURL string
+	// MaxSSEClients caps concurrent /api/events connections. Zero means the default.
+	MaxSSEClients int
+	// SSERetry is the base reconnect delay sent to event stream clients. Zero means the default.
+	SSERetry time.Duration
+	// SSEBuffer is the number of events queued per event stream client before
+	// further events are dropped for it. Zero means 10.
+	SSEBuffer int
+	// DefaultStatusTypes lists the item types that take part in the status lifecycle
+	// and receive DefaultStatus when they have none. Empty means Keep notes only.
+	DefaultStatusTypes []string
+	// MaxBodyBytes caps request bodies on POST and PUT endpoints. Zero means 1MB.
+	MaxBodyBytes int64
+	// FetchTimeout bounds each registry refresh so a stuck Google call cannot
+	// stall the poller. Zero means 30s.
+	FetchTimeout time.Duration
+	// RefreshRetries caps the retries shared by every upstream call in one
+	// registry refresh, so an outage across sources cannot multiply retries.
+	// Zero means 3.
+	RefreshRetries int
+	// NoteCacheTTL is how long a fetched note is reused by /api/notes/detail.
+	// Edits and deletes drop the cached copy early. Zero means 30s.
+	NoteCacheTTL time.Duration
+	// CacheJitter spreads registry cache expiry by up to this fraction of the
+	// TTL either way, so instances and triggers do not refresh in lockstep.
+	// Must be below 1. Zero means 0.1; negative turns jitter off.
+	CacheJitter float64
+	// MinRefreshInterval is the least time between forced registry refreshes
+	// (?refresh= and deletes). Requests inside the window are served from cache
+	// with an X-Refresh-Throttled header. Zero means 2s; negative turns
+	// throttling off.
+	MinRefreshInterval time.Duration
+	// DBFallback chooses what happens when the database cannot be opened: empty
+	// fails startup, "memory" keeps state in RAM only, losing it on restart.
+	DBFallback string
+	// DataDir holds the SQLite database and the legacy JSON state file. It is
+	// created if missing. Empty means the working directory.
+	DataDir string
+	// VacuumInterval is how often the database is compacted. Zero means daily.
+	VacuumInterval time.Duration
+	// DefaultMode is the mode on a fresh install, before any mode is stored.
+	// AUTO or MANUAL; empty means AUTO.
+	DefaultMode string
+	// SkipWarmCache starts serving without first filling the registry cache, so
+	// the first client pays for the initial fetch instead.
+	SkipWarmCache bool
+	// ImpersonationToken turns on switching the acting subject through
+	// /api/admin/impersonate; every request must send it as a bearer token.
+	// Empty leaves switching off.
+	ImpersonationToken string
+	// ImpersonationSubjects limits the subjects that may be switched to. Empty
+	// allows any subject in the domain.
+	ImpersonationSubjects []string
+	// Statuses replaces the lifecycle status vocabulary. It must include the
+	// default status, and its order is the order items advance through. Empty
+	// means the built-in set (Pending through Error).
+	Statuses []string
+}
+
+// lifecycle returns the statuses in the order items advance through them.
+func (c Config) lifecycle() []string {
+	if len(c.Statuses) == 0 {
+		return defaultLifecycle
+	}
+	return c.Statuses
+}
+
+// statusSet returns the configured statuses as a lookup set.
+func (c Config) statusSet() map[string]bool {
+	list := c.Statuses
+	if len(list) == 0 {
+		list = defaultStatuses
+	}
+	set := make(map[string]bool, len(list))
+	for _, status := range list {
+		set[status] = true
+	}
+	return set
+}
+
+// Validate reports whether the configuration can be used to start the server.
+func (c Config) Validate() error {
+	seen := make(map[string]bool, len(c.Statuses))
+	for _, status := range c.Statuses {
+		if strings.TrimSpace(status) == "" {
+			return errors.New("invalid empty status name")
+		}
+		if seen[status] {
+			return fmt.Errorf("duplicate status %q", status)
+		}
+		seen[status] = true
+	}
+	allowed := c.statusSet()
+	if c.DefaultStatus != "" && !allowed[c.DefaultStatus] {
+		return fmt.Errorf("invalid default status %q", c.DefaultStatus)
+	}
+	if c.DefaultStatus == "" && !allowed[defaultItemStatus] {
+		return fmt.Errorf("statuses must include the default status %q", defaultItemStatus)
+	}
+	if c.DefaultMode != "" && c.DefaultMode != "AUTO" && c.DefaultMode != "MANUAL" {
+		return fmt.Errorf("invalid default mode %q (want AUTO or MANUAL)", c.DefaultMode)
+	}
+	if c.PersistMode != "" && !validPersistMode(c.PersistMode) {
+		return fmt.Errorf("invalid persist mode %q (want %s or %s)", c.PersistMode, persistModeSync, persistModeAsync)
+	}
+	if c.MaxSSEClients < 0 {
+		return fmt.Errorf("invalid max SSE clients %d", c.MaxSSEClients)
+	}
+	if c.SSERetry < 0 {
+		return fmt.Errorf("invalid SSE retry %v", c.SSERetry)
+	}
+	if c.SSEBuffer < 0 {
+		return fmt.Errorf("invalid SSE buffer %d", c.SSEBuffer)
+	}
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("invalid max body size %d", c.MaxBodyBytes)
+	}
+	if c.FetchTimeout < 0 {
+		return fmt.Errorf("invalid fetch timeout %v", c.FetchTimeout)
+	}
+	if c.RefreshRetries < 0 {
+		return fmt.Errorf("invalid refresh retries %d", c.RefreshRetries)
+	}
+	if c.NoteCacheTTL < 0 {
+		return fmt.Errorf("invalid note cache TTL %v", c.NoteCacheTTL)
+	}
+	if c.DBFallback != "" && c.DBFallback != dbFallbackMemory {
+		return fmt.Errorf("invalid database fallback %q; want %q or empty", c.DBFallback, dbFallbackMemory)
+	}
+	if c.CacheJitter >= 1 {
+		return fmt.Errorf("invalid cache jitter %v; want a fraction below 1", c.CacheJitter)
+	}
+	if len(c.ImpersonationSubjects) > 0 && c.ImpersonationToken == "" {
+		return errors.New("impersonation subjects are set but no impersonation token is")
+	}
+	if c.VacuumInterval < 0 {
+		return fmt.Errorf("invalid vacuum interval %v", c.VacuumInterval)
+	}
+	for _, t := range c.DefaultStatusTypes {
+		if !itemTypes[t] {
+			return fmt.Errorf("invalid default status type %q", t)
+		}
+	}
+	return nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.DefaultStatus == "" {
+		c.DefaultStatus = defaultItemStatus
+	}
+	if c.DefaultMode == "" {
+		c.DefaultMode = "AUTO"
+	}
+	if c.PersistMode == "" {
+		c.PersistMode = persistModeAsync
+	}
+	if c.MaxSSEClients == 0 {
+		c.MaxSSEClients = defaultMaxSSEClients
+	}
+	if c.SSERetry == 0 {
+		c.SSERetry = defaultSSERetry
+	}
+	if c.SSEBuffer == 0 {
+		c.SSEBuffer = defaultSSEBuffer
+	}
+	if c.MaxBodyBytes == 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if c.FetchTimeout == 0 {
+		c.FetchTimeout = defaultFetchTimeout
+	}
+	if c.RefreshRetries == 0 {
+		c.RefreshRetries = defaultRefreshRetries
+	}
+	if c.NoteCacheTTL == 0 {
+		c.NoteCacheTTL = defaultNoteCacheTTL
+	}
+	if c.CacheJitter == 0 {
+		c.CacheJitter = defaultCacheJitter
+	}
+	if c.MinRefreshInterval == 0 {
+		c.MinRefreshInterval = defaultMinRefresh
+	}
+	if c.DataDir == "" {
+		c.DataDir = "."
+	}
+	if c.VacuumInterval == 0 {
+		c.VacuumInterval = defaultVacuumInterval
+	}
+	if len(c.DefaultStatusTypes) == 0 {
+		c.DefaultStatusTypes = []string{"keep"}
+	}
+	return c
 }
 
 // RegistryCache stores the latest registry snapshot with a TTL.
 type RegistryCache struct {
 	items     []workspace.RegistryItem
 	expiresAt time.Time
-	mu        sync.RWMutex
+	// hashes fingerprints items re-cached from detail fetches (see itemHash),
+	// so an unchanged item does not trigger a broadcast. Reset on refresh.
+	hashes map[string]string
+	// refreshedAt is when the last successful upstream fetch was installed.
+	refreshedAt time.Time
+	// loaded is set once a fetch has been installed for the current identity,
+	// so an empty registry is known to be empty rather than not yet listed.
+	loaded bool
+	mu     sync.RWMutex
 }
 
 // SSEMessage wraps data with an optional event type.
@@ -58,24 +319,104 @@ type SSEMessage struct {
 
 // persistentState defines the structure for disk storage.
 type persistentState struct {
-	Mode     string            `json:"mode"`
-	Statuses map[string]string `json:"statuses"`
+	Mode        string            `json:"mode"`
+	Statuses    map[string]string `json:"statuses"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // Server handles HTTP communication and TUI orchestration.
 type Server struct {
 	ws       *workspace.Service
+	wsMu     sync.RWMutex
+	pool     *workspace.ServicePool
+	subject  string
 	db       *database.DB
 	user     *workspace.User
 	mode     string
 	statuses map[string]string
 	modeMu   sync.RWMutex
+	// homeSubject is the subject the stored statuses belong to.
+	homeSubject string
+	// impersonationToken authorizes /api/admin/impersonate. Empty disables it.
+	impersonationToken string
+	// impersonationSubjects is the subject allowlist, lower-cased. Nil allows any.
+	impersonationSubjects map[string]bool
+	// paused freezes the AUTO countdown without leaving AUTO. Not persisted.
+	paused bool
+	// countdown mirrors the poller's remaining ticks for /api/mode/countdown.
+	countdown atomic.Int64
+
+	// annotations holds optional free-text notes explaining an item's status.
+	annotations map[string]string
+	// protected holds the IDs that every delete handler refuses.
+	protected map[string]bool
+
+	defaultStatus string
+	statusTypes   map[string]bool
+	// allowedStatuses is the configured lifecycle vocabulary.
+	allowedStatuses map[string]bool
+	webhook         *webhookNotifier
+	// lifecycle orders the statuses for advance and regress.
+	lifecycle []string
+
+	// dataDir holds the database and legacy state file.
+	dataDir string
+	// dbInMemory is set when the database file could not be opened and the
+	// configured fallback keeps state in memory instead.
+	dbInMemory   bool
+	persistMode  string
+	persistEvery time.Duration
+	// persistMu keeps database flushes and vacuums from overlapping.
+	persistMu   sync.Mutex
+	vacuumEvery time.Duration
+	dirty       map[string]bool
+	modeDirty   bool
+
+	reconcileOnce sync.Once
+
+	pendingDeletes   map[string]time.Time
+	pendingDeletesMu sync.Mutex
+	itemLocks        itemLocks
+
+	refreshSignal chan struct{}
+	// driveChangesToken is the Drive changes cursor of driveChangesWS, the
+	// service it was taken with. Guarded by driveChangesMu.
+	driveChangesToken string
+	driveChangesWS    *workspace.Service
+	driveChangesMu    sync.Mutex
+	driveWebhookURL   string
+	driveToken        string
+	// driveRewatch tells runDriveWatch to re-register for a new subject.
+	driveRewatch chan struct{}
 
 	registryCache RegistryCache
-
-	clients   map[chan SSEMessage]bool
-	clientsMu sync.Mutex
-	logger    *slog.Logger
+	snippets      snippetCache
+	sheetValues   sheetValuesCache
+	noteDetails   noteDetailCache
+	noteCacheTTL  time.Duration
+	cacheJitter   float64
+	minRefresh    time.Duration
+	// lastForcedRefresh is when forceRefresh last let a refresh through.
+	lastForcedRefresh time.Time
+	forcedRefreshMu   sync.Mutex
+	// random returns a value in [0, 1) for cache jitter. Nil means math/rand.
+	random func() float64
+
+	clients       map[chan SSEMessage]*sseClient
+	clientsMu     sync.Mutex
+	clientSeq     int
+	maxSSEClients int
+	sseRetry      time.Duration
+	sseBuffer     int
+	// droppedEvents counts events dropped across all clients. Guarded by clientsMu.
+	droppedEvents int
+	maxBodyBytes  int64
+	fetchTimeout  time.Duration
+	retries       int
+	warmCache     bool
+	logger        *slog.Logger
+	clock         clock
+	health        healthState
 
 	telemetryBuffer chan string
 }
@@ -87,33 +428,110 @@ type UserResponse struct {
 	ID    string `json:"id"`
 }
 
+// CountdownResponse reports the time left until the next AUTO refresh.
+type CountdownResponse struct {
+	Mode             string `json:"mode"`
+	SecondsRemaining int    `json:"seconds_remaining"`
+	Paused           bool   `json:"paused"`
+}
+
 // ModeResponse wraps the mode string for JSON output.
 type ModeResponse struct {
-	Mode string `json:"mode"`
+	Mode   string `json:"mode"`
+	Paused bool   `json:"paused"`
 }
 
 // NewServer initializes the server with the workspace service and user context.
-func NewServer(ws *workspace.Service, user *workspace.User) *Server {
+func NewServer(ws *workspace.Service, user *workspace.User, cfg Config) (*Server, error) {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	cfg = cfg.withDefaults()
 
-	db, err := database.NewDB(dbFileName)
+	db, err := openDB(cfg.DataDir)
+	inMemory := false
 	if err != nil {
-		logger.Error("failed to initialize database", "error", err)
-		os.Exit(1)
+		if cfg.DBFallback != dbFallbackMemory {
+			return nil, err
+		}
+		logger.Error("DATABASE UNAVAILABLE: running on an in-memory database; statuses, mode and protections will be lost on restart",
+			"dir", cfg.DataDir, "error", err)
+		if db, err = database.NewMemoryDB(); err != nil {
+			return nil, fmt.Errorf("in-memory database fallback: %w", err)
+		}
+		inMemory = true
 	}
 
 	s := &Server{
 		ws:              ws,
 		db:              db,
+		dbInMemory:      inMemory,
 		user:            user,
-		mode:            "AUTO",
+		mode:            cfg.DefaultMode,
 		statuses:        make(map[string]string),
-		clients:         make(map[chan SSEMessage]bool),
+		annotations:     make(map[string]string),
+		protected:       make(map[string]bool),
+		defaultStatus:   cfg.DefaultStatus,
+		allowedStatuses: cfg.statusSet(),
+		lifecycle:       cfg.lifecycle(),
+		statusTypes:     make(map[string]bool, len(cfg.DefaultStatusTypes)),
+		webhook:         newWebhookNotifier(cfg.WebhookURL, logger),
+		persistMode:     cfg.PersistMode,
+		persistEvery:    persistInterval,
+		clients:         make(map[chan SSEMessage]*sseClient),
+		maxSSEClients:   cfg.MaxSSEClients,
+		sseRetry:        cfg.SSERetry,
+		sseBuffer:       cfg.SSEBuffer,
+		maxBodyBytes:    cfg.MaxBodyBytes,
+		fetchTimeout:    cfg.FetchTimeout,
+		retries:         cfg.RefreshRetries,
+		noteCacheTTL:    cfg.NoteCacheTTL,
+		cacheJitter:     max(cfg.CacheJitter, 0),
+		minRefresh:      max(cfg.MinRefreshInterval, 0),
+		warmCache:       !cfg.SkipWarmCache,
+		dataDir:         cfg.DataDir,
+		vacuumEvery:     cfg.VacuumInterval,
+		refreshSignal:   make(chan struct{}, 1),
+		driveRewatch:    make(chan struct{}, 1),
 		logger:          logger,
+		clock:           realClock{},
 		telemetryBuffer: make(chan string, 100),
 	}
+	if cfg.DriveWebhookURL != "" {
+		token, err := newChannelToken()
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to generate drive channel token: %w", err)
+		}
+		s.driveWebhookURL = cfg.DriveWebhookURL
+		s.driveToken = token
+	}
+	s.impersonationToken = cfg.ImpersonationToken
+	if len(cfg.ImpersonationSubjects) > 0 {
+		s.impersonationSubjects = make(map[string]bool, len(cfg.ImpersonationSubjects))
+		for _, subject := range cfg.ImpersonationSubjects {
+			s.impersonationSubjects[strings.ToLower(subject)] = true
+		}
+	}
+	if ws != nil {
+		ws.SetLogger(logger)
+	}
+	s.countdown.Store(autoRefreshTicks)
+	for _, t := range cfg.DefaultStatusTypes {
+		s.statusTypes[t] = true
+	}
 	s.loadState()
-	return s
+	return s, nil
+}
+
+// openDB opens the database file in dir, creating dir if needed.
+func openDB(dir string) (*database.DB, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory %s: %w", dir, err)
+	}
+	db, err := database.NewDB(filepath.Join(dir, dbFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
 }
 
 // loadState restores mode/statuses from SQLite, migrating from JSON if necessary.
@@ -121,16 +539,24 @@ func (s *Server) loadState() {
 	start := time.Now()
 
 	// 1. Check if we need to migrate from JSON
-	if _, err := os.Stat(stateFileName); err == nil {
-		s.logger.Info("found legacy state file, migrating to SQLite...")
-		s.migrateFromJSON()
+	if _, err := os.Stat(s.statePath()); err == nil {
+		migrated, err := s.db.LegacyMigrated()
+		switch {
+		case err != nil:
+			s.logger.Error("failed to check legacy migration marker", "error", err)
+		case migrated:
+			s.logger.Warn("legacy state file found but already migrated; ignoring it", "path", s.statePath())
+		default:
+			s.logger.Info("found legacy state file, migrating to SQLite...")
+			s.migrateFromJSON()
+		}
 	}
 
-	// 2. Load mode from DB
+	// 2. Load mode from DB; without a stored mode the configured default stays
 	mode, err := s.db.GetMode()
 	if err != nil {
 		s.logger.Error("failed to load mode from db", "error", err)
-	} else {
+	} else if mode != "" {
 		s.mode = mode
 	}
 
@@ -142,12 +568,32 @@ func (s *Server) loadState() {
 		s.statuses = statuses
 	}
 
+	annotations, err := s.db.GetAnnotations()
+	if err != nil {
+		s.logger.Error("failed to load annotations from db", "error", err)
+	} else {
+		s.annotations = annotations
+	}
+
+	protected, err := s.db.GetProtected()
+	if err != nil {
+		s.logger.Error("failed to load protected items from db", "error", err)
+	} else {
+		s.protected = protected
+	}
+
 	s.logger.Info("state restored from SQLite", "duration", time.Since(start), "items", len(s.statuses))
 }
 
-// migrateFromJSON reads the legacy JSON state and persists it to SQLite.
+// statePath is the location of the legacy JSON state file inside the data directory.
+func (s *Server) statePath() string {
+	return filepath.Join(s.dataDir, stateFileName)
+}
+
+// migrateFromJSON reads the legacy JSON state and persists it to SQLite. Values
+// already in the database are kept, so a rerun never overwrites newer state.
 func (s *Server) migrateFromJSON() {
-	data, err := os.ReadFile(stateFileName)
+	data, err := os.ReadFile(s.statePath())
 	if err != nil {
 		s.logger.Error("failed to read legacy state file", "error", err)
 		return
@@ -159,30 +605,29 @@ func (s *Server) migrateFromJSON() {
 		return
 	}
 
-	if ps.Mode != "" {
-		if err := s.db.SetMode(ps.Mode); err != nil {
-			s.logger.Error("failed to migrate mode", "error", err)
+	statuses := make(map[string]string, len(ps.Statuses))
+	for id, status := range ps.Statuses {
+		// Migrate old state values to new ones
+		if status == "Keep" || status == "Delete" {
+			status = s.defaultStatus
+		}
+		if !s.allowedStatuses[status] {
+			status = s.defaultStatus
 		}
+		statuses[id] = status
 	}
 
-	if ps.Statuses != nil {
-		for id, status := range ps.Statuses {
-			// Migrate old state values to new ones
-			if status == "Keep" || status == "Delete" {
-				status = "Pending"
-			}
-			if _, ok := allowedStatuses[status]; !ok {
-				status = "Pending"
-			}
-			if err := s.db.SetStatus(id, status); err != nil {
-				s.logger.Error("failed to migrate status", "id", id, "error", err)
-			}
-		}
+	// One transaction: a failure leaves no partial import and no completion marker.
+	imported, err := s.db.ImportLegacyState(ps.Mode, statuses, ps.Annotations)
+	if err != nil {
+		s.logger.Error("failed to migrate legacy state", "error", err)
+		return
 	}
+	s.logger.Info("legacy state imported", "statuses", imported, "skipped", len(statuses)-imported)
 
-	// Backup legacy file
-	backupName := stateFileName + ".bak"
-	if err := os.Rename(stateFileName, backupName); err != nil {
+	// Timestamped so an earlier backup is never overwritten.
+	backupName := fmt.Sprintf("%s.%s.bak", s.statePath(), s.now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(s.statePath(), backupName); err != nil {
 		s.logger.Error("failed to backup legacy state file", "error", err)
 	} else {
 		s.logger.Info("legacy state migrated and backed up", "backup", backupName)
@@ -194,35 +639,104 @@ func (s *Server) Start(port string) error {
 	mux := http.NewServeMux()
 
 	// API Routes
+	mux.HandleFunc("/api/notes", s.handleNotes)
+	mux.HandleFunc("/api/notes/search", s.withRequestTimeout(s.handleNoteSearch))
 	mux.HandleFunc("/api/notes/delete", s.handleDelete)
-	mux.HandleFunc("/api/notes/detail", s.handleNoteDetail)
+	mux.HandleFunc("/api/notes/batch-delete", s.handleBatchDelete)
+	mux.HandleFunc("/api/notes/detail", s.withRequestTimeout(s.handleNoteDetail))
+	mux.HandleFunc("/api/notes/content", s.handleNoteContent)
+	mux.HandleFunc("/api/notes/content-batch", s.handleNoteContentBatch)
+	mux.HandleFunc("/api/notes/raw", s.handleNoteRaw)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/status/reset", s.handleStatusReset)
+	mux.HandleFunc("/api/status/summary", s.handleStatusSummary)
+	mux.HandleFunc("/api/status/transition", s.handleStatusTransition)
+	mux.HandleFunc("/api/status/advance", s.handleStatusAdvance)
+	mux.HandleFunc("/api/status/regress", s.handleStatusRegress)
+	mux.HandleFunc("/api/state/flush", s.handleStateFlush)
+	mux.HandleFunc("/api/state/export", s.handleStateExport)
+	mux.HandleFunc("/api/state/vacuum", s.handleStateVacuum)
 	mux.HandleFunc("/api/mode", s.handleMode)
+	mux.HandleFunc("/api/mode/pause", s.handlePause)
+	mux.HandleFunc("/api/mode/resume", s.handleResume)
+	mux.HandleFunc("/api/mode/countdown", s.handleCountdown)
 	mux.HandleFunc("/api/user", s.handleUser)
-	mux.HandleFunc("/api/sheets/detail", s.handleGetSheet)
+	mux.HandleFunc("/api/health/detail", s.handleHealthDetail)
+	mux.HandleFunc("/api/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("/api/admin/impersonate", s.handleImpersonate)
+	mux.HandleFunc("/api/sheets/detail", s.withRequestTimeout(s.handleGetSheet))
+	mux.HandleFunc("/api/sheets/values/page", s.withRequestTimeout(s.handleSheetValuesPage))
+	mux.HandleFunc("/api/sheets/meta", s.withRequestTimeout(s.handleSheetMeta))
 	mux.HandleFunc("/api/sheets/delete", s.handleDeleteSheet)
-	mux.HandleFunc("/api/docs/detail", s.handleGetDoc)
+	mux.HandleFunc("/api/docs/detail", s.withRequestTimeout(s.handleGetDoc))
 	mux.HandleFunc("/api/docs/delete", s.handleDeleteDoc)
-	mux.HandleFunc("/api/gmail/detail", s.handleGetGmailThread)
+	mux.HandleFunc("/api/docs/comments", s.handleDocComments)
+	mux.HandleFunc("/api/docs/revisions", s.handleDocRevisions)
+	mux.HandleFunc("/api/forms/detail", s.withRequestTimeout(s.handleGetForm))
+	mux.HandleFunc("/api/forms/delete", s.handleDeleteForm)
+	mux.HandleFunc("/api/gmail/detail", s.withRequestTimeout(s.handleGetGmailThread))
 	mux.HandleFunc("/api/gmail/delete", s.handleDeleteGmailThread)
-	mux.HandleFunc("/api/registry", s.handleRegistry)
+	mux.HandleFunc("/api/registry", s.withRequestTimeout(s.handleRegistry))
+	mux.HandleFunc("/api/registry/live", s.withRequestTimeout(s.handleRegistryLive))
+	mux.HandleFunc("/api/registry/export.ndjson", s.handleRegistryExport)
+	mux.HandleFunc("/api/registry/export.csv", s.handleRegistryExportCSV)
+	mux.HandleFunc("/api/registry/delete", s.handleDeleteItem)
+	mux.HandleFunc("/api/registry/protect", s.handleProtect)
+	mux.HandleFunc("/api/registry/unprotect", s.handleUnprotect)
 	// Google Chat Webhook
 	mux.HandleFunc("/api/chat/webhook", s.handleChatWebhook)
 
+	// Google Drive push notifications
+	if s.driveWebhookURL != "" {
+		mux.HandleFunc("/api/drive/webhook", s.handleDriveWebhook)
+	}
+
 	// SSE Endpoint
 	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/events/resync", s.handleEventsResync)
+	mux.HandleFunc("/api/events/clients", s.handleEventsClients)
 
 	// Static Asset Mounting
 	fileServer := http.FileServer(http.Dir("./web/dist"))
 	mux.Handle("/", fileServer)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if s.warmCache {
+		s.warmRegistryCache(ctx)
+	}
 
 	go s.runPoller(ctx)
 	go s.runTelemetryFlusher(ctx)
+	go s.runClientReaper(ctx)
+	go s.runVacuum(ctx)
+	if s.driveWebhookURL != "" {
+		go s.runDriveWatch(ctx)
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		s.runStateFlusher(ctx)
+		close(flushed)
+	}()
+
+	httpSrv := &http.Server{Addr: ":" + port, Handler: s.limitBody(mux)}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpSrv.Shutdown(shutdownCtx)
+	}()
 
-	s.logger.Info("axis server active", "port", port, "sse", true)
-	return http.ListenAndServe(":"+port, mux)
+	s.logger.Info("axis server active", "port", port, "sse", true, "persist", s.persistMode)
+	err := httpSrv.ListenAndServe()
+	stop()
+	<-flushed
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
 }
 
 func (s *Server) bufferTelemetry(msg string) {
@@ -235,7 +749,7 @@ func (s *Server) bufferTelemetry(msg string) {
 
 // runTelemetryFlusher processes periodically batches telemetry events and sends them via Chat API.
 func (s *Server) runTelemetryFlusher(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := s.newTicker(10 * time.Second)
 	defer ticker.Stop()
 
 	var batch []string
@@ -246,13 +760,13 @@ func (s *Server) runTelemetryFlusher(ctx context.Context) {
 			return
 		case msg := <-s.telemetryBuffer:
 			batch = append(batch, msg)
-		case <-ticker.C:
+		case <-ticker.C():
 			if len(batch) > 0 {
 				digest := "🔔 *System Telemetry Digest*\n"
 				for _, m := range batch {
 					digest += "- " + m + "\n"
 				}
-				err := s.ws.SendDirectMessage(s.user.Email, digest)
+				err := s.workspace().SendDirectMessage(s.currentUser().Email, digest)
 				if err != nil {
 					s.logger.Error("failed to send telemetry dm", "error", err)
 				}
@@ -264,65 +778,271 @@ func (s *Server) runTelemetryFlusher(ctx context.Context) {
 
 // runPoller processes periodic refreshes for AUTO mode.
 func (s *Server) runPoller(ctx context.Context) {
-	ticker := time.NewTicker(pollInterval)
+	ticker := s.newTicker(pollInterval)
 	defer ticker.Stop()
 
 	remaining := autoRefreshTicks
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			s.modeMu.RLock()
-			mode := s.mode
+			mode, paused := s.mode, s.paused
 			s.modeMu.RUnlock()
 
+			if mode == "AUTO" && paused {
+				continue
+			}
 			if mode == "AUTO" {
 				remaining--
+				s.countdown.Store(int64(remaining))
 				s.broadcastTick(remaining)
 				if remaining <= 0 {
-					s.refreshRegistryCache()
+					s.pollRegistry(ctx)
 					s.broadcastRegistry()
 					remaining = autoRefreshTicks
 				}
 			} else {
 				remaining = autoRefreshTicks
 			}
+		case <-s.refreshSignal:
+			s.refreshAndBroadcast()
+			remaining = autoRefreshTicks
 		case <-ctx.Done():
 			return
 		}
+		s.countdown.Store(int64(remaining))
 	}
 }
 
-func (s *Server) refreshRegistryCache() {
-	start := time.Now()
-	items, err := s.ws.ListRegistryItems()
+// pollRegistry is the poller's periodic refresh. Drive is only relisted when its
+// changes feed reports activity since the last poll; Keep has no changes feed and
+// Gmail is cheap, so both are always refetched.
+func (s *Server) pollRegistry(ctx context.Context) {
+	ctx, cancel := s.withFetchTimeout(ctx)
+	defer cancel()
+	ws := s.workspace()
+	if cursor := s.driveCursor(ws); cursor != "" {
+		changed, next, err := ws.DriveChangedSince(ctx, cursor)
+		if err == nil && !changed {
+			s.setDriveCursor(ws, next)
+			s.refreshNonDriveItems(ctx)
+			return
+		}
+		if err != nil {
+			s.logger.Warn("drive changes check failed, doing a full refresh", "error", err)
+		}
+	}
+
+	// Take the token before listing so changes made during the list are seen next time.
+	token, err := ws.DriveStartPageToken(ctx)
+	if err != nil {
+		s.logger.Warn("drive changes token unavailable", "error", err)
+		token = ""
+	}
+	s.setDriveCursor(ws, token)
+	s.refreshRegistryCache(ctx)
+}
+
+// driveCursor returns the stored Drive changes token if it was taken with ws.
+// A cursor from another identity would hide that identity's changes.
+func (s *Server) driveCursor(ws *workspace.Service) string {
+	s.driveChangesMu.Lock()
+	defer s.driveChangesMu.Unlock()
+	if s.driveChangesWS != ws {
+		return ""
+	}
+	return s.driveChangesToken
+}
+
+// setDriveCursor stores the Drive changes token taken with ws.
+func (s *Server) setDriveCursor(ws *workspace.Service, token string) {
+	s.driveChangesMu.Lock()
+	defer s.driveChangesMu.Unlock()
+	s.driveChangesWS = ws
+	s.driveChangesToken = token
+}
+
+// refreshNonDriveItems refetches Keep and Gmail while reusing the cached Drive items.
+func (s *Server) refreshNonDriveItems(ctx context.Context) {
+	ctx, cancel := s.withFetchTimeout(ctx)
+	defer cancel()
+	ctx = s.withRetryBudget(ctx)
+	start := s.now()
+	ws := s.workspace()
+	items, err := ws.ListKeepRegistryItems(ctx)
+	if err != nil {
+		s.logFetchError(ctx, err)
+		return
+	}
+	gmailItems, err := ws.ListGmailRegistryItems(ctx)
+	if err != nil {
+		s.logFetchError(ctx, err)
+		return
+	}
+
+	s.registryCache.mu.RLock()
+	for _, item := range s.registryCache.items {
+		if driveItemTypes[item.Type] {
+			items = append(items, item)
+		}
+	}
+	s.registryCache.mu.RUnlock()
+
+	s.applyRegistryItems(append(items, gmailItems...), start, nil)
+}
+
+// cacheExpiry returns when a registry cache filled now should expire: cacheTTL
+// from now, moved by a random amount of up to cacheJitter of the TTL either way.
+func (s *Server) cacheExpiry() time.Time {
+	random := s.random
+	if random == nil {
+		random = rand.Float64
+	}
+	jitter := time.Duration((2*random() - 1) * s.cacheJitter * float64(cacheTTL))
+	return s.now().Add(cacheTTL + jitter)
+}
+
+// refreshRegistryCache relists the registry. Handlers pass the request context so
+// a disconnecting client cancels the fetch; background callers pass their own.
+// Either way the fetch is bounded by the fetch timeout and shares one retry
+// budget. On failure the cache is left as it was for the next cycle to retry;
+// when only some sources fail, their types keep their cached items.
+func (s *Server) refreshRegistryCache(ctx context.Context) error {
+	ctx, cancel := s.withFetchTimeout(ctx)
+	defer cancel()
+	ctx = s.withRetryBudget(ctx)
+	start := s.now()
+	var failed []string
+	items, err := s.workspace().ListRegistryItems(ctx)
 	if err != nil {
-		s.logger.Error("workspace fetch failed", "error", err)
+		s.logFetchError(ctx, err)
+		var partial *workspace.PartialListError
+		if !errors.As(err, &partial) {
+			return err
+		}
+		items = s.appendCachedTypes(items, partial.Types)
+		failed = partial.Types
+	}
+	s.fillSnippets(ctx, items)
+	s.applyRegistryItems(items, start, failed)
+	return err
+}
+
+// warmRegistryCache fills the registry cache before the server starts listening,
+// so the first client's snapshot is served from cache. A failure is logged and
+// left for the first request or poll to retry.
+func (s *Server) warmRegistryCache(ctx context.Context) {
+	start := s.now()
+	if err := s.refreshRegistryCache(ctx); err != nil {
+		s.logger.Warn("registry cache warm failed; continuing with a cold cache", "error", err)
+		return
+	}
+	s.logger.Info("registry cache warmed", "duration", s.now().Sub(start))
+}
+
+// throttledHeader marks a response whose forced refresh was skipped because
+// another ran within the minimum refresh interval.
+const throttledHeader = "X-Refresh-Throttled"
+
+// forceRefresh refreshes the registry on a client's behalf unless a forced
+// refresh already ran within the minimum interval, in which case the cache is
+// left as is and false is returned.
+func (s *Server) forceRefresh(ctx context.Context) bool {
+	now := s.now()
+
+	s.forcedRefreshMu.Lock()
+	if !s.lastForcedRefresh.IsZero() && now.Sub(s.lastForcedRefresh) < s.minRefresh {
+		s.forcedRefreshMu.Unlock()
+		s.logger.Info("forced refresh throttled", "since", now.Sub(s.lastForcedRefresh))
+		return false
+	}
+	s.lastForcedRefresh = now
+	s.forcedRefreshMu.Unlock()
+
+	s.refreshRegistryCache(ctx)
+	return true
+}
+
+// withRetryBudget gives one refresh its own budget of upstream retries.
+func (s *Server) withRetryBudget(ctx context.Context) context.Context {
+	return workspace.WithRetryBudget(ctx, workspace.NewRetryBudget(s.retries))
+}
+
+// appendCachedTypes appends the cached items of the given types to items, standing
+// in for sources that failed to list.
+func (s *Server) appendCachedTypes(items []workspace.RegistryItem, types []string) []workspace.RegistryItem {
+	s.registryCache.mu.RLock()
+	defer s.registryCache.mu.RUnlock()
+	for _, item := range s.registryCache.items {
+		if slices.Contains(types, item.Type) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// withFetchTimeout bounds a registry fetch by the configured fetch timeout.
+func (s *Server) withFetchTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.fetchTimeout)
+}
+
+func (s *Server) logFetchError(ctx context.Context, err error) {
+	s.health.recordAPIError(err, s.now())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		s.logger.Error("workspace fetch timed out", "timeout", s.fetchTimeout, "error", err)
 		return
 	}
+	s.logger.Error("workspace fetch failed", "error", err)
+}
+
+// applyRegistryItems installs a freshly fetched registry in the cache, reconciling
+// statuses and broadcasting the diff against the previous snapshot. failed names
+// the types whose source could not be listed; their statuses are never pruned,
+// and the one-time startup reconciliation waits for a complete listing.
+func (s *Server) applyRegistryItems(items []workspace.RegistryItem, start time.Time, failed []string) {
+	items = s.filterPendingDeletes(items)
+
+	// Another subject's registry says nothing about which of ours still exist.
+	owned := s.ownsStatuses()
+	if owned && len(failed) == 0 {
+		s.reconcileOnce.Do(func() { s.reconcileStoredStatuses(items) })
+	}
 
-	needsSnapshot := s.backfillKeepStatuses(items)
+	needsSnapshot := s.backfillStatuses(items)
 
 	// Clean up statuses for notes that no longer exist
-	if s.cleanupStaleStatuses(items) {
+	if owned && s.cleanupStaleStatuses(items, failed) {
 		needsSnapshot = true
 	}
 
 	s.registryCache.mu.Lock()
+	previous := s.registryCache.items
 	s.registryCache.items = cloneItems(items)
-	s.registryCache.expiresAt = time.Now().Add(cacheTTL)
+	s.registryCache.hashes = nil
+	s.registryCache.refreshedAt = s.now()
+	s.registryCache.expiresAt = s.cacheExpiry()
+	s.registryCache.loaded = true
 	s.registryCache.mu.Unlock()
 
 	if needsSnapshot {
 		s.triggerStateSnapshot()
 	}
 
-	s.logger.Info("cache refreshed", "duration", time.Since(start), "count", len(items))
+	if previous != nil {
+		if diff := diffRegistry(previous, items); !diff.Empty() {
+			s.broadcastRegistryDiff(diff)
+		}
+	}
+
+	s.logger.Info("cache refreshed", "duration", s.now().Sub(start), "count", len(items))
 }
 
 func (s *Server) cachedItemsFresh() ([]workspace.RegistryItem, bool) {
 	s.registryCache.mu.RLock()
 	defer s.registryCache.mu.RUnlock()
-	fresh := time.Now().Before(s.registryCache.expiresAt)
+	// An empty cache is only fresh when a fetch actually came back empty.
+	fresh := s.now().Before(s.registryCache.expiresAt) &&
+		(s.registryCache.loaded || len(s.registryCache.items) > 0)
 	return cloneItems(s.registryCache.items), fresh
 }
 
@@ -341,20 +1061,44 @@ func (s *Server) enrichItems(items []workspace.RegistryItem) []workspace.Registr
 
 	res := make([]workspace.RegistryItem, len(items))
 	for i, item := range items {
-		res[i] = item
-		if status, ok := s.statuses[item.ID]; ok {
-			res[i].Status = status
-		} else if item.Type == "keep" {
-			res[i].Status = "Pending"
-		}
+		res[i] = s.enrichItemLocked(item)
 	}
 	return res
 }
 
+// enrichItemLocked applies the status and annotation for one item. Callers must hold modeMu.
+func (s *Server) enrichItemLocked(item workspace.RegistryItem) workspace.RegistryItem {
+	if status, ok := s.statuses[item.ID]; ok {
+		item.Status = status
+	} else if s.tracksStatus(item.Type) {
+		item.Status = s.defaultStatus
+	}
+	item.Annotation = s.annotations[item.ID]
+	item.Protected = s.protected[item.ID]
+	return item
+}
+
+// broadcast fans a message out to every connected SSE client without blocking.
+func (s *Server) broadcast(msg SSEMessage) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for clientChan, client := range s.clients {
+		if !client.wants(msg.Event) {
+			continue
+		}
+		select {
+		case clientChan <- msg:
+		default:
+			client.dropped++
+			s.droppedEvents++
+		}
+	}
+}
+
 func (s *Server) broadcastRegistry() {
-	items, _ := s.cachedItemsFresh()
-	if len(items) == 0 {
-		s.refreshRegistryCache()
+	items, fresh := s.cachedItemsFresh()
+	if len(items) == 0 && !fresh {
+		s.refreshRegistryCache(context.Background())
 		items, _ = s.cachedItemsFresh()
 	}
 	data, err := json.Marshal(s.enrichItems(items))
@@ -363,71 +1107,43 @@ func (s *Server) broadcastRegistry() {
 		return
 	}
 
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Data: data}:
-		default:
-		}
-	}
+	s.broadcast(SSEMessage{Data: data})
 }
 
 func (s *Server) broadcastTick(remaining int) {
 	data := []byte(fmt.Sprintf(`{"seconds_remaining": %d}`, remaining))
 
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Event: "tick", Data: data}:
-		default:
-		}
+	s.broadcast(SSEMessage{Event: "tick", Data: data})
+}
+
+// broadcastState tells clients the mode or pause state changed.
+func (s *Server) broadcastState(state ModeResponse) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		s.logger.Error("state marshal failed", "error", err)
+		return
 	}
+	s.broadcast(SSEMessage{Event: "state", Data: data})
 }
 
-func (s *Server) broadcastStatusChange(id, status, title string) {
+// broadcastStatusChange announces a status change. previous is the status before
+// the change and is omitted when the item had none.
+func (s *Server) broadcastStatusChange(id, previous, status, title string) {
 	payload := map[string]string{
 		"id":     id,
 		"status": status,
 		"title":  title,
 	}
+	if previous != "" {
+		payload["previous"] = previous
+	}
 	data, err := json.Marshal(payload)
 	if err != nil {
 		s.logger.Error("status change marshal failed", "error", err)
 		return
 	}
 
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Event: "status", Data: data}:
-		default:
-		}
-	}
-}
-
-func (s *Server) triggerStateSnapshot() {
-	s.modeMu.RLock()
-	mode := s.mode
-	statuses := make(map[string]string, len(s.statuses))
-	for k, v := range s.statuses {
-		statuses[k] = v
-	}
-	s.modeMu.RUnlock()
-
-	// Persist mode
-	if err := s.db.SetMode(mode); err != nil {
-		s.logger.Error("failed to persist mode", "error", err)
-	}
-
-	// Persist statuses
-	for id, status := range statuses {
-		if err := s.db.SetStatus(id, status); err != nil {
-			s.logger.Error("failed to persist status", "id", id, "error", err)
-		}
-	}
+	s.broadcast(SSEMessage{Event: "status", Data: data})
 }
 
 func (s *Server) isManualMode() bool {
@@ -447,47 +1163,66 @@ func (s *Server) getItemTitle(id string) string {
 	return ""
 }
 
-func (s *Server) backfillKeepStatuses(items []workspace.RegistryItem) bool {
+// tracksStatus reports whether items of the given type take part in the status lifecycle.
+func (s *Server) tracksStatus(itemType string) bool {
+	return s.statusTypes[itemType]
+}
+
+func (s *Server) backfillStatuses(items []workspace.RegistryItem) bool {
 	needSnapshot := false
 	s.modeMu.Lock()
 	var newItems []workspace.RegistryItem
 	for _, item := range items {
-		if item.Type != "keep" {
+		if !s.tracksStatus(item.Type) {
 			continue
 		}
 		if _, exists := s.statuses[item.ID]; exists {
 			continue
 		}
-		s.statuses[item.ID] = "Pending"
+		s.statuses[item.ID] = s.defaultStatus
+		s.markDirty(item.ID)
 		needSnapshot = true
 		newItems = append(newItems, item)
 	}
 	s.modeMu.Unlock()
 
-	// Broadcast telemetry for new notes initialized to Pending
+	// Broadcast telemetry for new items initialized to the default status
 	for _, item := range newItems {
-		s.broadcastStatusChange(item.ID, "Pending", item.Title)
+		s.broadcastStatusChange(item.ID, "", s.defaultStatus, item.Title)
 	}
 
 	return needSnapshot
 }
 
-// cleanupStaleStatuses removes statuses for keep notes that no longer exist
-func (s *Server) cleanupStaleStatuses(items []workspace.RegistryItem) bool {
-	// Build a set of current keep note IDs
-	keepIDs := make(map[string]bool)
+// cleanupStaleStatuses removes statuses for keep notes that no longer exist.
+// When some types failed to list, only statuses the previous cache places in a
+// type that did list are removed; any other may belong to the failed source.
+func (s *Server) cleanupStaleStatuses(items []workspace.RegistryItem, failed []string) bool {
+	// Build a set of current IDs for the types that carry a status
+	trackedIDs := make(map[string]bool)
 	for _, item := range items {
-		if item.Type == "keep" {
-			keepIDs[item.ID] = true
+		if s.tracksStatus(item.Type) {
+			trackedIDs[item.ID] = true
 		}
 	}
+	var prunable map[string]bool
+	if len(failed) > 0 {
+		prunable = s.cachedIDsOutside(failed)
+	}
 
 	needSnapshot := false
+	// Hold persistMu so an in-flight flush cannot write removed statuses back.
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
 	s.modeMu.Lock()
 	for id := range s.statuses {
-		// If this status is for a keep note that no longer exists, remove it
-		if !keepIDs[id] {
+		if prunable != nil && !prunable[id] {
+			continue
+		}
+		// If this status is for an item that no longer exists, remove it
+		if !trackedIDs[id] {
 			delete(s.statuses, id)
+			delete(s.annotations, id)
 			s.db.DeleteStatus(id)
 			needSnapshot = true
 			s.logger.Info("removed stale status", "id", id)
@@ -497,6 +1232,56 @@ func (s *Server) cleanupStaleStatuses(items []workspace.RegistryItem) bool {
 	return needSnapshot
 }
 
+// cachedIDsOutside returns the IDs of cached items whose type is not in types.
+func (s *Server) cachedIDsOutside(types []string) map[string]bool {
+	s.registryCache.mu.RLock()
+	defer s.registryCache.mu.RUnlock()
+	ids := make(map[string]bool, len(s.registryCache.items))
+	for _, item := range s.registryCache.items {
+		if !slices.Contains(types, item.Type) {
+			ids[item.ID] = true
+		}
+	}
+	return ids
+}
+
+// reconcileStoredStatuses prunes persisted statuses that have no matching registry
+// item. It runs once, after the first successful refresh, so an API outage at
+// startup can never wipe stored state.
+func (s *Server) reconcileStoredStatuses(items []workspace.RegistryItem) {
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item.ID] = true
+	}
+
+	stored, err := s.db.GetStatuses()
+	if err != nil {
+		s.logger.Error("status reconciliation skipped", "error", err)
+		return
+	}
+
+	// Hold persistMu so an in-flight flush cannot write pruned statuses back.
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+	pruned := 0
+	for id := range stored {
+		if known[id] {
+			continue
+		}
+		if err := s.db.DeleteStatus(id); err != nil {
+			s.logger.Error("failed to prune orphaned status", "id", id, "error", err)
+			continue
+		}
+		s.modeMu.Lock()
+		delete(s.statuses, id)
+		delete(s.annotations, id)
+		s.modeMu.Unlock()
+		pruned++
+	}
+
+	s.logger.Info("startup status reconciliation complete", "pruned", pruned, "stored", len(stored))
+}
+
 func (s *Server) ensureStatusDefault(id, defaultStatus string) (string, bool) {
 	s.modeMu.Lock()
 	defer s.modeMu.Unlock()
@@ -506,37 +1291,57 @@ func (s *Server) ensureStatusDefault(id, defaultStatus string) (string, bool) {
 	}
 
 	s.statuses[id] = defaultStatus
+	s.markDirty(id)
 	return defaultStatus, true
 }
 
 func (s *Server) statusForKeep(id string) string {
-	status, created := s.ensureStatusDefault(id, "Pending")
+	status, created := s.ensureStatusDefault(id, s.defaultStatus)
 	if created {
 		s.triggerStateSnapshot()
 	}
 	return status
 }
 
-func (s *Server) ensureKeepNoteCached(id, title string) bool {
+// ensureKeepNoteCached adds or refreshes a Keep note in the registry cache. It
+// reports whether the item is new or its title, status, or snippet changed, i.e.
+// whether a registry broadcast is warranted.
+func (s *Server) ensureKeepNoteCached(id, title string, collaborators int) bool {
 	if id == "" {
 		return false
 	}
 
-	status, created := s.ensureStatusDefault(id, "Pending")
+	status, created := s.ensureStatusDefault(id, s.defaultStatus)
 	needSnapshot := created
-	added := false
+	changed := false
 	item := workspace.RegistryItem{
 		ID:      id,
 		Type:    "keep",
-		Title:   sanitizeNoteTitle(title),
+		Title:   workspace.SanitizeNoteTitle(title),
 		Snippet: "Google Keep Note",
 		Status:  status,
+
+		Shared:        collaborators > 0,
+		Collaborators: collaborators,
 	}
 
+	hash := itemHash(item)
 	s.registryCache.mu.Lock()
 	replaced := false
 	for i := range s.registryCache.items {
 		if s.registryCache.items[i].ID == id {
+			previous, ok := s.registryCache.hashes[id]
+			if !ok {
+				// Cached items carry no status until enriched; compare against the current one.
+				cached := s.registryCache.items[i]
+				cached.Status = status
+				previous = itemHash(cached)
+			}
+			changed = previous != hash
+
+			// Keep listing metadata the detail fetch does not carry.
+			item.CreatedTime = s.registryCache.items[i].CreatedTime
+			item.ModifiedTime = s.registryCache.items[i].ModifiedTime
 			s.registryCache.items[i] = item
 			replaced = true
 			break
@@ -544,24 +1349,26 @@ func (s *Server) ensureKeepNoteCached(id, title string) bool {
 	}
 	if !replaced {
 		s.registryCache.items = append(s.registryCache.items, item)
-		added = true
+		changed = true
+	}
+	if s.registryCache.hashes == nil {
+		s.registryCache.hashes = make(map[string]string)
 	}
-	s.registryCache.expiresAt = time.Now().Add(cacheTTL)
+	s.registryCache.hashes[id] = hash
+	s.registryCache.expiresAt = s.cacheExpiry()
 	s.registryCache.mu.Unlock()
 
 	if needSnapshot {
 		s.triggerStateSnapshot()
 	}
 
-	return added
+	return changed
 }
 
-func sanitizeNoteTitle(raw string) string {
-	t := strings.TrimSpace(raw)
-	if t == "" {
-		return "Untitled"
-	}
-	return t
+// itemHash fingerprints the fields a registry update shows for an item.
+func itemHash(item workspace.RegistryItem) string {
+	sum := sha256.Sum256([]byte(item.Title + "\x00" + item.Status + "\x00" + item.Snippet))
+	return hex.EncodeToString(sum[:])
 }
 
 func truthyParam(v string) bool {
@@ -573,68 +1380,461 @@ func truthyParam(v string) bool {
 	}
 }
 
-func (s *Server) handleNoteDetail(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+// writeJSON sends v as the JSON response with the given status. It marshals v
+// before writing anything, so an encoding failure still gets a clean 500 rather
+// than a 200 with a truncated body.
+func (s *Server) writeJSON(w http.ResponseWriter, code int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.logger.Error("failed to encode response", "error", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(append(data, '\n'))
+}
 
-	note, err := s.ws.GetNote(r.Context(), id)
+// allowMethods reports whether r uses one of methods. Otherwise it writes a 405
+// with the matching Allow header and the caller should return.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// requireJSON reports whether r declares a JSON body. Otherwise it writes a 415
+// and the caller should return. A charset parameter is allowed.
+func requireJSON(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
+
+// limitBody rejects POST and PUT requests whose body exceeds maxBodyBytes with a
+// 413. Bodies of unknown length are capped so reads past the limit fail.
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.maxBodyBytes > 0 && (r.Method == http.MethodPost || r.Method == http.MethodPut) {
+			if r.ContentLength > s.maxBodyBytes {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bodyErrorStatus maps a body read error to 413 when the size limit was hit.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// parsePageParam reads a non-negative integer query parameter, returning def when absent.
+func parsePageParam(r *http.Request, name string, def int) (int, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return n, nil
+}
+
+// pageLinks builds an RFC 8288 Link header value for limit/offset pagination.
+// Query parameters of u other than limit and offset are kept in the links.
+func pageLinks(u *url.URL, limit, offset, total int) string {
+	link := func(at int, rel string) string {
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(at))
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, q.Encode(), rel)
+	}
+	var links []string
+	if offset+limit < total {
+		links = append(links, link(offset+limit, "next"))
+	}
+	if offset > 0 {
+		links = append(links, link(max(offset-limit, 0), "prev"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// pageOf returns the limit items starting at offset, or everything from offset
+// when limit is zero. The result is never nil.
+func pageOf[T any](items []T, limit, offset int) []T {
+	total := len(items)
+	offset = min(offset, total)
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	if page := items[offset:end]; page != nil {
+		return page
+	}
+	return []T{}
+}
+
+// setPageHeaders sends the total count and, when paginating, the Link header.
+func setPageHeaders(w http.ResponseWriter, r *http.Request, limit, offset, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if limit > 0 {
+		if links := pageLinks(r.URL, limit, offset, total); links != "" {
+			w.Header().Set("Link", links)
+		}
+	}
+}
+
+// parseLimitOffset reads the ?limit= and ?offset= pagination parameters.
+func parseLimitOffset(r *http.Request) (limit, offset int, err error) {
+	if limit, err = parsePageParam(r, "limit", 0); err != nil {
+		return 0, 0, err
+	}
+	if offset, err = parsePageParam(r, "offset", 0); err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+func (s *Server) handleNotes(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	limit, offset, err := parseLimitOffset(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if note != nil {
-		added := s.ensureKeepNoteCached(note.Name, note.Title)
-		if added {
-			s.broadcastRegistry()
+	notes, err := s.workspace().ListAllNoteSummaries(r.Context(), workspace.ListNotesOptions{})
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+
+	setPageHeaders(w, r, limit, offset, len(notes))
+	s.writeJSON(w, http.StatusOK, pageOf(notes, limit, offset))
+}
+
+// NoteSearchResult is one match of /api/notes/search. Body holds the full note
+// content and is only sent when the caller asks for it.
+type NoteSearchResult struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+	Body    string `json:"body,omitempty"`
+}
+
+// handleNoteSearch returns the notes whose title or content contains every term
+// of ?q=, paginated like /api/notes. ?includeBody=true adds each note's content.
+func (s *Server) handleNoteSearch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "missing q", http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parseLimitOffset(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	includeBody := truthyParam(r.URL.Query().Get("includeBody"))
+
+	notes, err := s.workspace().SearchNotes(r.Context(), query)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+
+	page := pageOf(notes, limit, offset)
+	results := make([]NoteSearchResult, 0, len(page))
+	for _, note := range page {
+		summary := workspace.SummarizeNote(note)
+		res := NoteSearchResult{ID: summary.ID, Title: summary.Title, Snippet: summary.Snippet}
+		if includeBody {
+			res.Body = workspace.ExtractFullContent(note.Body)
 		}
+		results = append(results, res)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(note); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	setPageHeaders(w, r, limit, offset, len(notes))
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleNoteDetail(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if note, ok := s.cachedNote(id); ok {
+		s.writeJSON(w, http.StatusOK, note)
+		return
+	}
+
+	note, err := s.workspace().GetNote(r.Context(), id)
+	if err != nil {
+		s.writeAPIError(w, err)
 		return
 	}
+
+	if note != nil {
+		s.storeNote(id, note)
+		if s.ensureKeepNoteCached(note.Name, note.Title, workspace.NoteCollaborators(note)) {
+			s.broadcastRegistry()
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, note)
 }
 
-func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+// handleNoteRaw returns the Keep API representation of a note untouched,
+// including attachments and permissions, without updating the registry cache.
+func (s *Server) handleNoteRaw(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
+	note, err := s.workspace().GetNote(r.Context(), id)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, note)
+}
+
+// NoteContentResult is one entry of a /api/notes/content-batch response.
+type NoteContentResult struct {
+	Content string `json:"content,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleNoteContentBatch returns the content and status of many notes at once,
+// keyed by id. Notes are fetched concurrently, each with its own timeout, and a
+// failed fetch is reported in its entry without failing the others.
+func (s *Server) handleNoteContentBatch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !requireJSON(w, r) {
+		return
+	}
+
+	var ids []string
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		http.Error(w, "invalid JSON body; want an array of ids", bodyErrorStatus(err))
+		return
+	}
+	if len(ids) == 0 {
+		http.Error(w, "no ids given", http.StatusBadRequest)
+		return
+	}
+	if len(ids) > maxBatchContent {
+		http.Error(w, fmt.Sprintf("too many ids (max %d)", maxBatchContent), http.StatusBadRequest)
+		return
+	}
+
+	ws := s.workspace()
+	results := make(map[string]NoteContentResult, len(ids))
+	var mu sync.Mutex
+	sem := make(chan struct{}, batchContentWorkers)
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		mu.Lock()
+		_, dup := results[id]
+		results[id] = NoteContentResult{}
+		mu.Unlock()
+		if dup {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(r.Context(), noteFetchTimeout)
+			defer cancel()
+			var res NoteContentResult
+			note, err := ws.GetNote(ctx, id)
+			if err != nil {
+				_, res.Error = friendlyAPIError(err)
+				s.logger.Warn("batch note fetch failed", "id", id, "error", err)
+			} else {
+				res.Content = workspace.ExtractFullContent(note.Body)
+				res.Status = s.currentStatus(id, "keep")
+			}
+			mu.Lock()
+			results[id] = res
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+// currentStatus returns the status of id without assigning a default, falling
+// back to the default status for item types that track one.
+func (s *Server) currentStatus(id, itemType string) string {
 	s.modeMu.RLock()
-	currentMode := s.mode
-	s.modeMu.RUnlock()
+	defer s.modeMu.RUnlock()
+	if status, ok := s.statuses[id]; ok {
+		return status
+	}
+	if s.tracksStatus(itemType) {
+		return s.defaultStatus
+	}
+	return ""
+}
+
+// NoteContentRequest is the body accepted by /api/notes/content.
+type NoteContentRequest struct {
+	Content string `json:"content"`
+}
 
-	if currentMode != "MANUAL" {
-		http.Error(w, "delete requires MANUAL mode", http.StatusForbidden)
+// handleNoteContent replaces a Keep note's text (PUT ?id=, MANUAL mode). Keep
+// cannot edit in place, so the note is recreated and the original deleted: the
+// edited note has a new ID, returned in the response. Its status moves with it
+// and the old ID is hidden until the listing catches up. The recreated note
+// loses the original's collaborators and attachments, so shared notes need
+// ?force=true (409 otherwise), and protected notes are refused with 403.
+func (s *Server) handleNoteContent(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPut) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "editing requires MANUAL mode", http.StatusForbidden)
 		return
 	}
 
-	if err := s.ws.DeleteNote(context.Background(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if !requireJSON(w, r) {
+		return
+	}
+	var req NoteContentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", bodyErrorStatus(err))
 		return
 	}
 
-	s.refreshRegistryCache()
-	s.broadcastRegistry()
-	w.WriteHeader(http.StatusOK)
+	// The edit deletes the original, so it is serialized and guarded like a delete.
+	unlock := s.itemLocks.lock(id)
+	if s.isProtected(id) {
+		unlock()
+		http.Error(w, errProtected, http.StatusForbidden)
+		return
+	}
+	msg, err := s.sharedNoteError(r.Context(), id, truthyParam(r.URL.Query().Get("force")))
+	if err != nil {
+		unlock()
+		s.writeAPIError(w, err)
+		return
+	}
+	if msg != "" {
+		unlock()
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	note, err := s.workspace().UpdateNoteBody(r.Context(), id, req.Content)
+	// Whatever happened upstream, the cached copy may no longer match it.
+	s.forgetNote(id)
+	if errors.Is(err, workspace.ErrChecklistNote) {
+		unlock()
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if note == nil {
+		unlock()
+		s.writeAPIError(w, err)
+		return
+	}
+	if err != nil {
+		// The edit landed but the original survived; surface both copies.
+		s.logger.Warn("note edit left the original in place", "id", id, "error", err)
+	} else {
+		s.markPendingDelete(id)
+		s.moveStatus(id, note.Name)
+	}
+	unlock()
+
+	if s.ensureKeepNoteCached(note.Name, note.Title, workspace.NoteCollaborators(note)) {
+		s.broadcastRegistry()
+	}
+
+	s.writeJSON(w, http.StatusOK, note)
+}
+
+// moveStatus carries the status and annotation of from over to to and forgets from.
+func (s *Server) moveStatus(from, to string) {
+	s.modeMu.Lock()
+	status, ok := s.statuses[from]
+	if ok {
+		s.statuses[to] = status
+		if annotation, ok := s.annotations[from]; ok {
+			s.annotations[to] = annotation
+		}
+		s.markDirty(to)
+	}
+	s.modeMu.Unlock()
+
+	s.forgetStatus(from)
+	s.triggerStateSnapshot()
 }
 
+// handleMode reports the mode on GET. Changing it (?set=) requires POST.
 func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
 	newMode := r.URL.Query().Get("set")
+	if newMode == "" {
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+	} else if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
 
 	s.modeMu.Lock()
 	if newMode == "" {
-		mode := s.mode
+		resp := ModeResponse{Mode: s.mode, Paused: s.paused}
 		s.modeMu.Unlock()
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ModeResponse{Mode: mode})
+		s.writeJSON(w, http.StatusOK, resp)
 		return
 	}
 
@@ -644,6 +1844,8 @@ func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.mode = newMode
+	s.paused = false
+	s.markModeDirty()
 	s.modeMu.Unlock()
 
 	if newMode == "MANUAL" {
@@ -651,41 +1853,194 @@ func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.triggerStateSnapshot()
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ModeResponse{Mode: newMode})
+	s.writeJSON(w, http.StatusOK, ModeResponse{Mode: newMode})
+}
+
+// handleCountdown reports the poller's current countdown, so a client that
+// connects between tick events need not wait for the next one.
+func (s *Server) handleCountdown(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	s.modeMu.RLock()
+	resp := CountdownResponse{Mode: s.mode, Paused: s.paused}
+	s.modeMu.RUnlock()
+	resp.SecondsRemaining = int(time.Duration(s.countdown.Load()) * pollInterval / time.Second)
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// handlePause freezes the AUTO countdown. The mode stays AUTO, so deletes remain
+// disabled; switching modes or resuming clears the pause.
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+// handleResume restarts a paused AUTO countdown from where it stopped.
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+func (s *Server) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	s.modeMu.Lock()
+	if paused && s.mode != "AUTO" {
+		s.modeMu.Unlock()
+		http.Error(w, "pause requires AUTO mode", http.StatusConflict)
+		return
+	}
+	s.paused = paused
+	resp := ModeResponse{Mode: s.mode, Paused: paused}
+	s.modeMu.Unlock()
+
+	s.broadcastState(resp)
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
 func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
-	if s.user == nil {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	user := s.currentUser()
+	if user == nil {
 		http.Error(w, "user profile unavailable", http.StatusServiceUnavailable)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(UserResponse{Name: s.user.Name, Email: s.user.Email, ID: s.user.ID})
+	s.writeJSON(w, http.StatusOK, UserResponse{Name: user.Name, Email: user.Email, ID: user.ID})
 }
 
 func (s *Server) handleRegistry(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := parseRegistryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	manual := s.isManualMode()
 	forceRefresh := manual && truthyParam(r.URL.Query().Get("refresh"))
 	if forceRefresh {
-		s.refreshRegistryCache()
-		s.broadcastRegistry()
+		if s.forceRefresh(r.Context()) {
+			s.broadcastRegistry()
+		} else {
+			w.Header().Set(throttledHeader, "true")
+		}
 	}
 
+	enriched := filter.apply(s.currentRegistry(r.Context()))
+	var body any = enriched
+	if len(fields) > 0 {
+		projected, err := projectItems(enriched, fields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = projected
+	}
+	s.writeJSON(w, http.StatusOK, body)
+}
+
+// handleRegistryExport streams the enriched registry as newline-delimited JSON,
+// one item per line. Items are enriched and flushed one at a time, so neither
+// the full enriched slice nor the full response is held in memory.
+func (s *Server) handleRegistryExport(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	items, ok := s.exportItems(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		s.modeMu.RLock()
+		item = s.enrichItemLocked(item)
+		s.modeMu.RUnlock()
+
+		if err := enc.Encode(item); err != nil {
+			s.logger.Warn("registry export aborted", "error", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// handleRegistryLive lists the registry straight from Google for debugging cache
+// staleness. Unlike ?refresh=, it neither updates the cache nor broadcasts.
+func (s *Server) handleRegistryLive(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	items, err := s.workspace().ListRegistryItems(r.Context())
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.enrichItems(items))
+}
+
+// currentRegistry returns the enriched registry, refreshing the cache first if it
+// is stale or not yet loaded.
+func (s *Server) currentRegistry(ctx context.Context) []workspace.RegistryItem {
 	items, fresh := s.cachedItemsFresh()
-	if !fresh || len(items) == 0 {
-		s.refreshRegistryCache()
+	if !fresh {
+		s.refreshRegistryCache(ctx)
 		items, _ = s.cachedItemsFresh()
 	}
+	return s.enrichItems(items)
+}
 
-	enriched := s.enrichItems(items)
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(enriched); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// StatusSummaryResponse aggregates lifecycle status and item type counts.
+type StatusSummaryResponse struct {
+	Statuses map[string]int `json:"statuses"`
+	Types    map[string]int `json:"types"`
+	Total    int            `json:"total"`
+}
+
+func (s *Server) handleStatusSummary(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	items := s.currentRegistry(r.Context())
+
+	resp := StatusSummaryResponse{
+		Statuses: make(map[string]int),
+		Types:    make(map[string]int),
+		Total:    len(items),
+	}
+	for _, item := range items {
+		resp.Types[item.Type]++
+		// Gmail items carry label summaries in Status; only lifecycle values count here.
+		if s.allowedStatuses[item.Status] {
+			resp.Statuses[item.Status]++
+		}
 	}
+
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
+// handleStatus sets an item's status and, with ?annotation=, a short note
+// explaining it. With ?sync=true the write reaches the database before the
+// response, even in async persist mode.
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	status := r.URL.Query().Get("status")
 
@@ -694,44 +2049,217 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, ok := allowedStatuses[status]; !ok {
+	if !s.allowedStatuses[status] {
 		http.Error(w, "invalid status", http.StatusBadRequest)
 		return
 	}
 
+	// An annotation is optional. When given (even empty) it replaces the
+	// current one; when omitted the existing annotation is kept.
+	setAnnotation := r.URL.Query().Has("annotation")
+	annotation := strings.TrimSpace(r.URL.Query().Get("annotation"))
+	if len(annotation) > maxAnnotationLen {
+		http.Error(w, fmt.Sprintf("annotation exceeds %d bytes", maxAnnotationLen), http.StatusBadRequest)
+		return
+	}
+
+	// Serialize with deletes of the same item so a status never outlives its item.
+	unlock := s.itemLocks.lock(id)
+	if s.isPendingDelete(id) {
+		unlock()
+		http.Error(w, "item is being deleted", http.StatusConflict)
+		return
+	}
 	s.modeMu.Lock()
+	previous := s.statuses[id]
 	s.statuses[id] = status
+	if setAnnotation {
+		if annotation == "" {
+			delete(s.annotations, id)
+		} else {
+			s.annotations[id] = annotation
+		}
+	}
+	s.markDirty(id)
 	s.modeMu.Unlock()
+	unlock()
+
+	// A synchronous write is announced only once it has been persisted.
+	if truthyParam(r.URL.Query().Get("sync")) {
+		if err := s.setStatusNow(id); err != nil {
+			s.logger.Error("failed to persist status", "id", id, "error", err)
+			http.Error(w, "failed to persist status", http.StatusInternalServerError)
+			return
+		}
+	}
+	s.announceStatus(id, previous, status)
+
+	s.triggerStateSnapshot()
+	s.broadcastRegistry()
+	w.WriteHeader(http.StatusOK)
+}
 
+// announceStatus notifies the webhook, event stream clients and telemetry that
+// id was set from previous to status.
+func (s *Server) announceStatus(id, previous, status string) {
 	// Look up the note title for telemetry
 	title := s.getItemTitle(id)
+	if previous != status {
+		s.webhook.Notify(StatusChangeEvent{
+			ID:        id,
+			Title:     title,
+			From:      previous,
+			To:        status,
+			Timestamp: s.now().UTC(),
+		})
+	}
 	if title != "" {
-		s.broadcastStatusChange(id, status, title)
+		s.broadcastStatusChange(id, previous, status, title)
 
 		if status == "Error" {
 			s.bufferTelemetry(fmt.Sprintf("Item %s ('%s') transitioned to Error state", id, title))
 		}
 	}
+}
 
-	s.triggerStateSnapshot()
+// StatusResetResponse reports how many status entries a reset cleared.
+type StatusResetResponse struct {
+	Cleared int `json:"cleared"`
+}
+
+// handleStatusReset wipes all status state so Keep notes fall back to the default
+// status and are re-backfilled on the next refresh.
+func (s *Server) handleStatusReset(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "status reset requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+	if !truthyParam(r.URL.Query().Get("confirm")) {
+		http.Error(w, "status reset requires confirm=true", http.StatusBadRequest)
+		return
+	}
+
+	// Hold persistMu so an in-flight flush cannot write cleared statuses back,
+	// and clear memory only once the database has been cleared.
+	s.persistMu.Lock()
+	if _, err := s.db.ClearStatuses(); err != nil {
+		s.persistMu.Unlock()
+		s.logger.Error("failed to clear statuses", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.modeMu.Lock()
+	cleared := len(s.statuses)
+	s.statuses = make(map[string]string)
+	s.annotations = make(map[string]string)
+	s.dirty = nil
+	s.modeMu.Unlock()
+	s.persistMu.Unlock()
+
+	s.logger.Info("statuses reset", "cleared", cleared)
 	s.broadcastRegistry()
-	w.WriteHeader(http.StatusOK)
+
+	s.writeJSON(w, http.StatusOK, StatusResetResponse{Cleared: cleared})
+}
+
+// StatusTransitionRequest is the body of a bulk status transition.
+type StatusTransitionRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// StatusTransitionResponse reports how many items a bulk transition moved.
+type StatusTransitionResponse struct {
+	Transitioned int `json:"transitioned"`
+}
+
+// handleStatusTransition moves every item currently in From to To. Each move is
+// announced like a single status change, and the registry is broadcast once.
+func (s *Server) handleStatusTransition(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !requireJSON(w, r) {
+		return
+	}
+	var req StatusTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body; want {\"from\": ..., \"to\": ...}", bodyErrorStatus(err))
+		return
+	}
+	if !s.allowedStatuses[req.From] || !s.allowedStatuses[req.To] {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+	if req.From == req.To {
+		http.Error(w, "from and to are the same status", http.StatusBadRequest)
+		return
+	}
+
+	var candidates []string
+	s.modeMu.RLock()
+	for id, status := range s.statuses {
+		if status == req.From {
+			candidates = append(candidates, id)
+		}
+	}
+	s.modeMu.RUnlock()
+
+	// Serialize each move with deletes and other status changes of the same item,
+	// re-checking the status since it may have changed since the scan.
+	var moved []string
+	for _, id := range candidates {
+		unlock := s.itemLocks.lock(id)
+		if !s.isPendingDelete(id) {
+			s.modeMu.Lock()
+			if s.statuses[id] == req.From {
+				s.statuses[id] = req.To
+				s.markDirty(id)
+				moved = append(moved, id)
+			}
+			s.modeMu.Unlock()
+		}
+		unlock()
+	}
+
+	for _, id := range moved {
+		s.announceStatus(id, req.From, req.To)
+	}
+
+	s.logger.Info("bulk status transition", "from", req.From, "to", req.To, "transitioned", len(moved))
+	if len(moved) > 0 {
+		s.triggerStateSnapshot()
+		s.broadcastRegistry()
+	}
+
+	s.writeJSON(w, http.StatusOK, StatusTransitionResponse{Transitioned: len(moved)})
 }
 
 func (s *Server) handleGetSheet(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
+	render := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("render")))
+	if render != "" && !workspace.ValidSheetRender(render) {
+		http.Error(w, "invalid render; want FORMATTED_VALUE, UNFORMATTED_VALUE or FORMULA", http.StatusBadRequest)
+		return
+	}
 
-	sheet, err := s.ws.GetSheet(id)
+	sheet, err := s.workspace().GetSheet(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.writeAPIError(w, err)
 		return
 	}
 
-	valuesResp, err := s.ws.GetSheetValues(id, "A1:Z100")
+	valuesResp, err := s.workspace().GetSheetValues(r.Context(), id, "A1:Z100", render)
 	var values [][]interface{}
 	if err == nil && valuesResp != nil {
 		values = valuesResp.Values
@@ -743,109 +2271,233 @@ func (s *Server) handleGetSheet(w http.ResponseWriter, r *http.Request) {
 		"values":        values,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	s.writeJSON(w, http.StatusOK, response)
 }
 
-func (s *Server) handleDeleteSheet(w http.ResponseWriter, r *http.Request) {
+// SheetMetaResponse lists the tabs, named ranges and charts of a spreadsheet.
+type SheetMetaResponse struct {
+	Title       string                 `json:"title"`
+	SheetTitles []string               `json:"sheetTitles"`
+	NamedRanges []workspace.NamedRange `json:"namedRanges"`
+	Charts      []workspace.Chart      `json:"charts"`
+}
+
+// handleSheetMeta describes the structure of sheet ?id= from a single
+// spreadsheet read, without fetching any values.
+func (s *Server) handleSheetMeta(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.ws.DeleteSheet(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	sheet, err := s.workspace().GetSheet(id)
+	if err != nil {
+		s.writeAPIError(w, err)
 		return
 	}
 
-	if s.isManualMode() {
-		s.refreshRegistryCache()
-		s.broadcastRegistry()
-	} else {
-		go s.refreshAndBroadcast()
+	resp := SheetMetaResponse{
+		SheetTitles: []string{},
+		NamedRanges: workspace.ListNamedRanges(sheet),
+		Charts:      workspace.ListCharts(sheet),
 	}
-	w.WriteHeader(http.StatusOK)
+	if sheet.Properties != nil {
+		resp.Title = sheet.Properties.Title
+	}
+	for _, tab := range sheet.Sheets {
+		if tab.Properties != nil {
+			resp.SheetTitles = append(resp.SheetTitles, tab.Properties.Title)
+		}
+	}
+	if resp.Charts == nil {
+		resp.Charts = []workspace.Chart{}
+	}
+	s.writeJSON(w, http.StatusOK, resp)
 }
 
 func (s *Server) handleGetDoc(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
+	// maxBytes caps the extracted text so very large documents stay cheap to serve.
+	maxBytes, err := parsePageParam(r, "maxBytes", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	doc, err := s.ws.GetDoc(id)
+	doc, err := s.workspace().GetDoc(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.writeAPIError(w, err)
 		return
 	}
 
-	content := ""
+	content, truncated := "", false
 	if doc.Body != nil {
-		content = workspace.ExtractDocContent(doc.Body.Content)
+		content, truncated = workspace.ExtractDocContentLimit(doc.Body.Content, maxBytes)
 	}
 
 	response := map[string]interface{}{
 		"title":      doc.Title,
 		"documentId": doc.DocumentId,
 		"content":    content,
+		"truncated":  truncated,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleGetForm(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	form, err := s.workspace().GetForm(id)
+	if errors.Is(err, workspace.ErrFormsUnavailable) {
+		http.Error(w, "forms support is disabled", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
 	}
+
+	title := ""
+	if form.Info != nil {
+		title = form.Info.Title
+	}
+
+	response := map[string]interface{}{
+		"title":   title,
+		"formId":  form.FormId,
+		"content": workspace.ExtractFormContent(form),
+	}
+
+	s.writeJSON(w, http.StatusOK, response)
 }
 
-func (s *Server) handleDeleteDoc(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleDocComments(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.ws.DeleteDoc(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	comments, err := s.workspace().ListDocComments(r.Context(), id)
+	if err != nil {
+		s.writeAPIError(w, err)
 		return
 	}
+	if comments == nil {
+		comments = []workspace.DocComment{}
+	}
 
-	if s.isManualMode() {
-		s.refreshRegistryCache()
-		s.broadcastRegistry()
-	} else {
-		go s.refreshAndBroadcast()
+	s.writeJSON(w, http.StatusOK, comments)
+}
+
+// handleDocRevisions lists who edited a doc and when, oldest revision first.
+func (s *Server) handleDocRevisions(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
 	}
-	w.WriteHeader(http.StatusOK)
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := s.workspace().ListDocRevisions(r.Context(), id)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+	if revisions == nil {
+		revisions = []workspace.DocRevision{}
+	}
+
+	s.writeJSON(w, http.StatusOK, revisions)
 }
 
-func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// sseRetryFor returns the reconnect delay to advertise with n clients connected.
+// It grows linearly from the base delay to four times the base as n nears the cap.
+func (s *Server) sseRetryFor(n int) time.Duration {
+	if s.maxSSEClients <= 0 {
+		return s.sseRetry
+	}
+	return s.sseRetry + s.sseRetry*time.Duration(3*n)/time.Duration(s.maxSSEClients)
+}
 
+// handleEvents streams registry, tick, and status events. ?events=status,tick
+// limits the stream to the named types; "registry" selects full snapshots.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
 
-	msgChan := make(chan SSEMessage, 10)
+	msgChan := make(chan SSEMessage, s.sseBuffer)
 	s.clientsMu.Lock()
-	s.clients[msgChan] = true
+	if s.maxSSEClients > 0 && len(s.clients) >= s.maxSSEClients {
+		s.clientsMu.Unlock()
+		s.logger.Warn("rejecting SSE client, limit reached", "limit", s.maxSSEClients, "remote", r.RemoteAddr)
+		http.Error(w, "too many event stream clients", http.StatusServiceUnavailable)
+		return
+	}
+	s.clientSeq++
+	client := newSSEClient()
+	client.id = fmt.Sprintf("sse-%d", s.clientSeq)
+	client.remoteAddr = r.RemoteAddr
+	client.connectedAt = s.now()
+	client.events = parseEventFilter(r.URL.Query().Get("events"))
+	s.clients[msgChan] = client
+	retry := s.sseRetryFor(len(s.clients))
 	s.clientsMu.Unlock()
 
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	// Tell the browser how long to wait before reconnecting so restarts don't
+	// bring every client back at once.
+	fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds())
+	fmt.Fprintf(w, "event: connected\ndata: {\"token\":%q}\n\n", client.token)
+	flusher.Flush()
+
+	// Removing the client under clientsMu stops every broadcast from reaching it.
+	// msgChan is deliberately never closed: the initial snapshot goroutine may
+	// still send after the handler returns, and an open buffered channel makes
+	// that send harmless instead of a panic. It is garbage collected with the client.
 	defer func() {
 		s.clientsMu.Lock()
 		delete(s.clients, msgChan)
 		s.clientsMu.Unlock()
-		close(msgChan)
 	}()
 
-	go s.sendInitialRegistrySnapshot(msgChan)
+	if client.wants(registryEvent) {
+		go s.sendInitialRegistrySnapshot(r.Context(), msgChan)
+	}
 
 	for {
 		select {
@@ -855,6 +2507,14 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 			}
 			fmt.Fprintf(w, "data: %s\n\n", msg.Data)
 			flusher.Flush()
+		case <-client.resync:
+			// Written directly rather than queued so a full channel cannot drop it again.
+			if data, ok := s.registrySnapshot(r.Context()); ok {
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			}
+		case <-client.done:
+			return
 		case <-r.Context().Done():
 			return
 		}
@@ -862,15 +2522,18 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleGetGmailThread(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	thread, err := s.ws.GetGmailThread(id)
+	thread, err := s.workspace().GetGmailThread(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.writeAPIError(w, err)
 		return
 	}
 
@@ -883,55 +2546,43 @@ func (s *Server) handleGetGmailThread(w http.ResponseWriter, r *http.Request) {
 		"raw":      thread,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	s.writeJSON(w, http.StatusOK, response)
 }
 
-func (s *Server) handleDeleteGmailThread(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
-		return
-	}
-
-	if err := s.ws.TrashGmailThread(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// sendInitialRegistrySnapshot queues the registry for a new client without
+// blocking. It is skipped once the client has disconnected.
+func (s *Server) sendInitialRegistrySnapshot(ctx context.Context, ch chan<- SSEMessage) {
+	data, ok := s.registrySnapshot(ctx)
+	if !ok || ctx.Err() != nil {
 		return
 	}
-
-	if s.isManualMode() {
-		s.refreshRegistryCache()
-		s.broadcastRegistry()
-	} else {
-		go s.refreshAndBroadcast()
+	select {
+	case ch <- SSEMessage{Data: data}:
+	default:
 	}
-	w.WriteHeader(http.StatusOK)
 }
 
-func (s *Server) sendInitialRegistrySnapshot(ch chan<- SSEMessage) {
+// registrySnapshot renders the enriched registry for an event stream client,
+// refreshing the cache first when it is stale or not yet loaded.
+func (s *Server) registrySnapshot(ctx context.Context) ([]byte, bool) {
 	items, fresh := s.cachedItemsFresh()
-	if !fresh || len(items) == 0 {
-		s.refreshRegistryCache()
-		items, _ = s.cachedItemsFresh()
+	if !fresh {
+		s.refreshRegistryCache(ctx)
+		items, fresh = s.cachedItemsFresh()
 	}
-	if len(items) == 0 {
-		return
+	if len(items) == 0 && !fresh {
+		return nil, false
 	}
 	data, err := json.Marshal(s.enrichItems(items))
 	if err != nil {
-		s.logger.Error("initial snapshot marshal failed", "error", err)
-		return
-	}
-	select {
-	case ch <- SSEMessage{Data: data}:
-	default:
+		s.logger.Error("registry snapshot marshal failed", "error", err)
+		return nil, false
 	}
+	return data, true
 }
 
 func (s *Server) refreshAndBroadcast() {
-	s.refreshRegistryCache()
+	s.refreshRegistryCache(context.Background())
 	s.broadcastRegistry()
 }
 
@@ -952,15 +2603,17 @@ type ChatEvent struct {
 
 // handleChatWebhook receives and processes events from Google Chat API.
 func (s *Server) handleChatWebhook(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !requireJSON(w, r) {
 		return
 	}
 
 	var event ChatEvent
 	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
 		s.logger.Error("failed to decode chat event", "error", err)
-		http.Error(w, "bad request", http.StatusBadRequest)
+		http.Error(w, "bad request", bodyErrorStatus(err))
 		return
 	}
 
@@ -985,8 +2638,5 @@ func (s *Server) handleChatWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		s.logger.Error("failed to encode chat response", "error", err)
-	}
+	s.writeJSON(w, http.StatusOK, response)
 }
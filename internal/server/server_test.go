@@ -9,16 +9,31 @@ content retrieval and normalized status lifecycle (Pending, Execute, Complete).
 package server
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"axis/internal/database"
 	"axis/internal/workspace"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	docs "google.golang.org/api/docs/v1"
+	drive "google.golang.org/api/drive/v3"
+	keep "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
 )
 
 func setupTestServer(t *testing.T) *Server {
@@ -38,18 +53,73 @@ func setupTestServer(t *testing.T) *Server {
 		os.Remove(f.Name())
 	})
 
+	cfg := Config{}.withDefaults()
 	s := &Server{
-		ws:       nil,
-		db:       db,
-		user:     &workspace.User{Name: "Test User", Email: "test@example.com", ID: "123"},
-		mode:     "AUTO",
-		statuses: make(map[string]string),
-		clients:  make(map[chan SSEMessage]bool),
-		logger:   slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		ws:          nil,
+		db:          db,
+		user:        &workspace.User{Name: "Test User", Email: "test@example.com", ID: "123"},
+		mode:        "AUTO",
+		statuses:    make(map[string]string),
+		annotations: make(map[string]string),
+		clients:     make(map[chan SSEMessage]*sseClient),
+		logger:      slog.New(slog.NewJSONHandler(io.Discard, nil)),
+
+		defaultStatus:   defaultItemStatus,
+		allowedStatuses: cfg.statusSet(),
+		lifecycle:       cfg.lifecycle(),
+		statusTypes:     map[string]bool{"keep": true},
+		persistMode:     persistModeAsync,
+		persistEvery:    persistInterval,
+		sseRetry:        cfg.SSERetry,
+		sseBuffer:       cfg.SSEBuffer,
+		fetchTimeout:    cfg.FetchTimeout,
+		retries:         cfg.RefreshRetries,
+		noteCacheTTL:    cfg.NoteCacheTTL,
 	}
 	return s
 }
 
+// newStubWorkspace builds a workspace service whose Admin, Keep, Drive, Docs, and
+// Sheets clients all talk to the supplied handler.
+func newStubWorkspace(t *testing.T, handler http.Handler) *workspace.Service {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+
+	adminSvc, err := admin.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keepSvc, err := keep.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	driveSvc, err := drive.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docsSvc, err := docs.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheetsSvc, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return workspace.NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, nil, nil, nil, nil)
+}
+
+// jsonRequest builds a request carrying a JSON body.
+func jsonRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
 func TestHandleMode(t *testing.T) {
 	s := setupTestServer(t)
 
@@ -71,7 +141,7 @@ func TestHandleMode(t *testing.T) {
 	}
 
 	// Test SET to MANUAL
-	req = httptest.NewRequest("GET", "/api/mode?set=MANUAL", nil)
+	req = httptest.NewRequest("POST", "/api/mode?set=MANUAL", nil)
 	rr = httptest.NewRecorder()
 	s.handleMode(rr, req)
 
@@ -83,7 +153,7 @@ func TestHandleMode(t *testing.T) {
 	}
 
 	// Test SET to invalid mode
-	req = httptest.NewRequest("GET", "/api/mode?set=INVALID", nil)
+	req = httptest.NewRequest("POST", "/api/mode?set=INVALID", nil)
 	rr = httptest.NewRecorder()
 	s.handleMode(rr, req)
 
@@ -92,6 +162,40 @@ func TestHandleMode(t *testing.T) {
 	}
 }
 
+func TestMutatingEndpointsRejectGet(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	s.statuses["notes/1"] = "Pending"
+
+	cases := []struct {
+		url     string
+		handler http.HandlerFunc
+		allow   string
+	}{
+		{"/api/status?id=notes/1&status=Active", s.handleStatus, "POST"},
+		{"/api/notes/delete?id=notes/1", s.handleDelete, "POST, DELETE"},
+		{"/api/registry/delete?id=notes/1", s.handleDeleteItem, "POST, DELETE"},
+		{"/api/mode?set=AUTO", s.handleMode, "POST"},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		tc.handler(rr, httptest.NewRequest("GET", tc.url, nil))
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %v", tc.url, rr.Code)
+		}
+		if got := rr.Header().Get("Allow"); got != tc.allow {
+			t.Errorf("%s: expected Allow %q, got %q", tc.url, tc.allow, got)
+		}
+	}
+
+	if s.statuses["notes/1"] != "Pending" {
+		t.Error("expected status to be unchanged by a rejected GET")
+	}
+	if s.mode != "MANUAL" {
+		t.Error("expected mode to be unchanged by a rejected GET")
+	}
+}
+
 func TestHandleUser(t *testing.T) {
 	s := setupTestServer(t)
 	req := httptest.NewRequest("GET", "/api/user", nil)
@@ -143,3 +247,1576 @@ func TestHandleStatus(t *testing.T) {
 		t.Errorf("expected 400 for invalid status, got %v", rr.Code)
 	}
 }
+
+func TestStatusEventCarriesPreviousStatus(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	ch := make(chan SSEMessage, 8)
+	s.clients[ch] = newSSEClient()
+
+	statusEvent := func() map[string]string {
+		t.Helper()
+		for {
+			select {
+			case msg := <-ch:
+				if msg.Event != "status" {
+					continue
+				}
+				var payload map[string]string
+				if err := json.Unmarshal(msg.Data, &payload); err != nil {
+					t.Fatal(err)
+				}
+				return payload
+			default:
+				t.Fatal("expected a status event")
+				return nil
+			}
+		}
+	}
+
+	s.handleStatus(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/status?id=notes/1&status=Pending", nil))
+	payload := statusEvent()
+	if _, ok := payload["previous"]; ok || payload["status"] != "Pending" {
+		t.Errorf("expected a first status without previous, got %v", payload)
+	}
+
+	s.handleStatus(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/status?id=notes/1&status=Active", nil))
+	payload = statusEvent()
+	if payload["previous"] != "Pending" || payload["status"] != "Active" {
+		t.Errorf("expected Pending -> Active, got %v", payload)
+	}
+}
+
+func TestBackfillUsesConfiguredDefaultStatus(t *testing.T) {
+	s := setupTestServer(t)
+	s.defaultStatus = "Review"
+
+	items := []workspace.RegistryItem{
+		{ID: "notes/new", Type: "keep", Title: "Fresh Note"},
+		{ID: "doc-1", Type: "doc", Title: "Some Doc"},
+	}
+	if !s.backfillStatuses(items) {
+		t.Fatal("expected backfill to request a snapshot")
+	}
+
+	if got := s.statuses["notes/new"]; got != "Review" {
+		t.Errorf("expected backfilled status Review, got %q", got)
+	}
+	if _, ok := s.statuses["doc-1"]; ok {
+		t.Error("expected non-keep items to be left without a status")
+	}
+
+	enriched := s.enrichItems([]workspace.RegistryItem{{ID: "notes/other", Type: "keep"}})
+	if enriched[0].Status != "Review" {
+		t.Errorf("expected enriched default Review, got %q", enriched[0].Status)
+	}
+}
+
+func TestDefaultStatusTypesIncludeDocs(t *testing.T) {
+	s := setupTestServer(t)
+	items := []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep", Title: "Note"},
+		{ID: "doc-1", Type: "doc", Title: "Doc"},
+		{ID: "sheet-1", Type: "sheet", Title: "Sheet"},
+	}
+
+	enriched := s.enrichItems(items)
+	if enriched[1].Status != "" {
+		t.Fatalf("expected docs to be left without a status by default, got %q", enriched[1].Status)
+	}
+
+	s.statusTypes = map[string]bool{"keep": true, "doc": true}
+	s.backfillStatuses(items)
+	if s.cleanupStaleStatuses(items, nil) {
+		t.Error("expected no stale statuses for tracked items")
+	}
+	enriched = s.enrichItems(items)
+	if enriched[0].Status != "Pending" || enriched[1].Status != "Pending" {
+		t.Errorf("expected keep and doc to default to Pending, got %+v", enriched)
+	}
+	if enriched[2].Status != "" {
+		t.Errorf("expected sheets to stay untracked, got %q", enriched[2].Status)
+	}
+	if s.statuses["doc-1"] != "Pending" {
+		t.Error("expected the doc status to be backfilled")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("expected empty config to be valid, got %v", err)
+	}
+	if err := (Config{DefaultStatus: "Review"}).Validate(); err != nil {
+		t.Errorf("expected Review to be valid, got %v", err)
+	}
+	if err := (Config{DefaultStatus: "Bogus"}).Validate(); err == nil {
+		t.Error("expected invalid default status to be rejected")
+	}
+	if err := (Config{DefaultMode: "LOCKED"}).Validate(); err == nil {
+		t.Error("expected an unknown default mode to be rejected")
+	}
+	if err := (Config{DefaultStatusTypes: []string{"keep", "doc"}}).Validate(); err != nil {
+		t.Errorf("expected keep and doc status types to be valid, got %v", err)
+	}
+	if err := (Config{DefaultStatusTypes: []string{"folder"}}).Validate(); err == nil {
+		t.Error("expected an unknown status type to be rejected")
+	}
+	if err := (Config{CacheJitter: 1}).Validate(); err == nil {
+		t.Error("expected a cache jitter of the whole TTL to be rejected")
+	}
+	if err := (Config{CacheJitter: -1, MinRefreshInterval: -1}).Validate(); err != nil {
+		t.Errorf("expected negative jitter and refresh interval to be valid, got %v", err)
+	}
+	if err := (Config{ImpersonationSubjects: []string{"a@example.com"}}).Validate(); err == nil {
+		t.Error("expected an impersonation allowlist without a token to be rejected")
+	}
+}
+
+func TestNegativeJitterAndRefreshIntervalTurnThemOff(t *testing.T) {
+	for _, tc := range []struct {
+		cfg        Config
+		jitter     float64
+		minRefresh time.Duration
+	}{
+		{Config{}, defaultCacheJitter, defaultMinRefresh},
+		{Config{CacheJitter: -1, MinRefreshInterval: -time.Second}, 0, 0},
+	} {
+		tc.cfg.DataDir = t.TempDir()
+		s, err := NewServer(nil, nil, tc.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.db.Close()
+		if s.cacheJitter != tc.jitter || s.minRefresh != tc.minRefresh {
+			t.Errorf("%+v: expected jitter %v and interval %v, got %v and %v",
+				tc.cfg, tc.jitter, tc.minRefresh, s.cacheJitter, s.minRefresh)
+		}
+	}
+}
+
+func TestCustomStatusSet(t *testing.T) {
+	cfg := Config{Statuses: []string{"Pending", "Triaged", "Escalated", "Complete"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the custom set to be valid, got %v", err)
+	}
+	if err := (Config{Statuses: []string{"Triaged", "Escalated"}}).Validate(); err == nil {
+		t.Error("expected a set without the default status to be rejected")
+	}
+	if err := (Config{Statuses: []string{"Triaged"}, DefaultStatus: "Triaged"}).Validate(); err != nil {
+		t.Errorf("expected a set holding the configured default to be valid, got %v", err)
+	}
+	if err := (Config{Statuses: []string{"Pending", "Pending"}}).Validate(); err == nil {
+		t.Error("expected duplicate statuses to be rejected")
+	}
+
+	s := setupTestServer(t)
+	s.allowedStatuses = cfg.statusSet()
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=notes/1&status=Escalated", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected a custom status to be accepted, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := s.statuses["notes/1"]; got != "Escalated" {
+		t.Errorf("expected Escalated, got %q", got)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleStatus(rr, httptest.NewRequest("POST", "/api/status?id=notes/1&status=Blocked", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a built-in status outside the custom set to be rejected, got %v", rr.Code)
+	}
+}
+
+func TestHandleNotesPagination(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": [
+			{"name": "notes/a", "title": "A"},
+			{"name": "notes/b", "title": "B"},
+			{"name": "notes/c", "title": "C"},
+			{"name": "notes/d", "title": "D"}
+		]}`))
+	}))
+
+	req := httptest.NewRequest("GET", "/api/notes?limit=2&offset=1", nil)
+	rr := httptest.NewRecorder()
+	s.handleNotes(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var notes []workspace.Note
+	if err := json.NewDecoder(rr.Body).Decode(&notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].ID != "notes/b" || notes[1].ID != "notes/c" {
+		t.Errorf("expected notes b and c, got %+v", notes)
+	}
+
+	if got := rr.Header().Get("X-Total-Count"); got != "4" {
+		t.Errorf("expected X-Total-Count 4, got %q", got)
+	}
+	wantLink := `</api/notes?limit=2&offset=3>; rel="next", </api/notes?limit=2&offset=0>; rel="prev"`
+	if got := rr.Header().Get("Link"); got != wantLink {
+		t.Errorf("expected Link %q, got %q", wantLink, got)
+	}
+
+	// No params returns everything without a Link header.
+	rr = httptest.NewRecorder()
+	s.handleNotes(rr, httptest.NewRequest("GET", "/api/notes", nil))
+	notes = nil
+	if err := json.NewDecoder(rr.Body).Decode(&notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 4 {
+		t.Errorf("expected all 4 notes, got %d", len(notes))
+	}
+	if got := rr.Header().Get("Link"); got != "" {
+		t.Errorf("expected no Link header, got %q", got)
+	}
+
+	// Invalid params are rejected.
+	rr = httptest.NewRecorder()
+	s.handleNotes(rr, httptest.NewRequest("GET", "/api/notes?limit=-1", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for negative limit, got %v", rr.Code)
+	}
+}
+
+func TestHandleStatusReset(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.statuses["notes/1"] = "Active"
+	s.statuses["notes/2"] = "Blocked"
+	s.db.SetStatus("notes/1", "Active")
+	s.db.SetStatus("notes/2", "Blocked")
+
+	// Refused outside MANUAL mode.
+	rr := httptest.NewRecorder()
+	s.handleStatusReset(rr, httptest.NewRequest("POST", "/api/status/reset?confirm=true", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 in AUTO mode, got %v", rr.Code)
+	}
+
+	s.mode = "MANUAL"
+
+	// Refused without confirmation.
+	rr = httptest.NewRecorder()
+	s.handleStatusReset(rr, httptest.NewRequest("POST", "/api/status/reset", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without confirm, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleStatusReset(rr, httptest.NewRequest("POST", "/api/status/reset?confirm=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+
+	var resp StatusResetResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cleared != 2 {
+		t.Errorf("expected 2 cleared, got %d", resp.Cleared)
+	}
+	if len(s.statuses) != 0 {
+		t.Errorf("expected in-memory statuses to be empty, got %v", s.statuses)
+	}
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 0 {
+		t.Errorf("expected DB statuses to be empty, got %v", statuses)
+	}
+}
+
+func TestHandleStatusResetKeepsStatusesWhenDatabaseFails(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+	s.statuses["notes/1"] = "Active"
+	s.annotations["notes/1"] = "keep"
+	s.dirty = map[string]bool{"notes/1": true}
+	s.db.Close()
+
+	rr := httptest.NewRecorder()
+	s.handleStatusReset(rr, httptest.NewRequest("POST", "/api/status/reset?confirm=true", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the database cannot be cleared, got %v", rr.Code)
+	}
+	if s.statuses["notes/1"] != "Active" || s.annotations["notes/1"] != "keep" {
+		t.Errorf("expected memory to keep matching the database, got statuses %v annotations %v", s.statuses, s.annotations)
+	}
+	if !s.dirty["notes/1"] {
+		t.Error("expected the pending write to stay queued")
+	}
+}
+
+func TestHandleStatusTransition(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	s.statuses["notes/1"] = "Pending"
+	s.statuses["notes/2"] = "Pending"
+	s.statuses["notes/3"] = "Pending"
+	s.statuses["notes/4"] = "Blocked"
+	ch := make(chan SSEMessage, 8)
+	s.clients[ch] = newSSEClient()
+
+	rr := httptest.NewRecorder()
+	s.handleStatusTransition(rr, jsonRequest("POST", "/api/status/transition", strings.NewReader(`{"from": "Pending", "to": "Execute"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var resp StatusTransitionResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Transitioned != 3 {
+		t.Errorf("expected 3 transitioned, got %d", resp.Transitioned)
+	}
+	for _, id := range []string{"notes/1", "notes/2", "notes/3"} {
+		if got := s.statuses[id]; got != "Execute" {
+			t.Errorf("expected %s to be Execute, got %q", id, got)
+		}
+	}
+	if got := s.statuses["notes/4"]; got != "Blocked" {
+		t.Errorf("expected notes/4 to stay Blocked, got %q", got)
+	}
+	if len(s.dirty) != 3 {
+		t.Errorf("expected 3 dirty entries, got %v", s.dirty)
+	}
+	var announced map[string]string
+	for len(ch) > 0 {
+		if msg := <-ch; msg.Event == "status" {
+			if err := json.Unmarshal(msg.Data, &announced); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if announced["id"] != "notes/1" || announced["previous"] != "Pending" || announced["status"] != "Execute" {
+		t.Errorf("expected a status event for the moved item, got %v", announced)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleStatusTransition(rr, jsonRequest("POST", "/api/status/transition", strings.NewReader(`{"from": "Pending", "to": "Bogus"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %v", rr.Code)
+	}
+}
+
+func TestStartupReconciliationPrunesOrphansAfterSuccessfulRefresh(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, `{"error": {"code": 503, "message": "unavailable"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			w.Write([]byte(`{"notes": [{"name": "notes/live", "title": "Live"}]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+
+	s.db.SetStatus("notes/live", "Active")
+	s.db.SetStatus("notes/stale", "Blocked")
+
+	// A failed refresh must not prune anything.
+	s.refreshRegistryCache(context.Background())
+	stored, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored["notes/stale"]; !ok {
+		t.Fatal("expected stale status to survive a failed refresh")
+	}
+
+	failing.Store(false)
+	s.refreshRegistryCache(context.Background())
+
+	stored, err = s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored["notes/stale"]; ok {
+		t.Error("expected stale status to be pruned after a successful refresh")
+	}
+	if stored["notes/live"] != "Active" {
+		t.Errorf("expected live status to be kept, got %q", stored["notes/live"])
+	}
+}
+
+func TestHandleStatusSummary(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep"},
+		{ID: "notes/2", Type: "keep"},
+		{ID: "notes/3", Type: "keep"},
+		{ID: "notes/4", Type: "keep"},
+		{ID: "doc-1", Type: "doc"},
+		{ID: "thread-1", Type: "gmail", Status: "UNREAD"},
+	}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	s.statuses["notes/1"] = "Active"
+	s.statuses["notes/2"] = "Complete"
+	s.statuses["doc-1"] = "Active"
+	// notes/3 and notes/4 have no explicit status and default to Pending.
+
+	rr := httptest.NewRecorder()
+	s.handleStatusSummary(rr, httptest.NewRequest("GET", "/api/status/summary", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+
+	var resp StatusSummaryResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	wantStatuses := map[string]int{"Active": 2, "Complete": 1, "Pending": 2}
+	if !reflect.DeepEqual(resp.Statuses, wantStatuses) {
+		t.Errorf("expected statuses %v, got %v", wantStatuses, resp.Statuses)
+	}
+	wantTypes := map[string]int{"keep": 4, "doc": 1, "gmail": 1}
+	if !reflect.DeepEqual(resp.Types, wantTypes) {
+		t.Errorf("expected types %v, got %v", wantTypes, resp.Types)
+	}
+	if resp.Total != 6 {
+		t.Errorf("expected total 6, got %d", resp.Total)
+	}
+}
+
+func TestHandleEventsClientLimit(t *testing.T) {
+	s := setupTestServer(t)
+	s.maxSSEClients = 2
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{}, s.maxSSEClients)
+	for i := 0; i < s.maxSSEClients; i++ {
+		go func() {
+			req := httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx)
+			s.handleEvents(httptest.NewRecorder(), req)
+			done <- struct{}{}
+		}()
+	}
+
+	clientCount := func() int {
+		s.clientsMu.Lock()
+		defer s.clientsMu.Unlock()
+		return len(s.clients)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for clientCount() < s.maxSSEClients && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := clientCount(); n != s.maxSSEClients {
+		t.Fatalf("expected %d connected clients, got %d", s.maxSSEClients, n)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleEvents(rr, httptest.NewRequest("GET", "/api/events", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 for the client over the limit, got %v", rr.Code)
+	}
+	if n := clientCount(); n != s.maxSSEClients {
+		t.Errorf("expected rejected client not to be registered, got %d clients", n)
+	}
+
+	cancel()
+	for i := 0; i < s.maxSSEClients; i++ {
+		<-done
+	}
+	if n := clientCount(); n != 0 {
+		t.Errorf("expected all clients to be removed on disconnect, got %d", n)
+	}
+}
+
+func TestHandleNoteContent(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/notes/old":
+			w.Write([]byte(`{"name": "notes/old", "title": "Plan", "body": {"text": {"text": "before"}}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/notes/shared":
+			w.Write([]byte(`{"name": "notes/shared", "title": "Team", "body": {"text": {"text": "before"}}, "permissions": [
+				{"role": "OWNER", "email": "me@example.com"}, {"role": "WRITER", "email": "a@example.com"}
+			]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/notes/list":
+			w.Write([]byte(`{"name": "notes/list", "title": "Todo", "body": {"list": {"listItems": []}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"name": "notes/new", "title": "Plan", "body": {"text": {"text": "after"}}}`))
+		case r.Method == http.MethodDelete:
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	s.statuses["notes/old"] = "Active"
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/old", Type: "keep", Title: "Plan"}}
+	s.registryCache.expiresAt = time.Now().Add(cacheTTL)
+
+	rr := httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/old", strings.NewReader(`{"content": "after"}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 outside MANUAL mode, got %v", rr.Code)
+	}
+
+	s.mode = "MANUAL"
+	rr = httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/old", strings.NewReader(`{"content": "after"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if s.statuses["notes/new"] != "Active" {
+		t.Errorf("expected status to follow the recreated note, got %q", s.statuses["notes/new"])
+	}
+	if _, ok := s.statuses["notes/old"]; ok {
+		t.Error("expected the old note's status to be removed")
+	}
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 1 || items[0].ID != "notes/new" {
+		t.Errorf("expected the cache to hold only the recreated note, got %+v", items)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/list", strings.NewReader(`{"content": "x"}`)))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a checklist note, got %v", rr.Code)
+	}
+
+	// Recreating a shared note would drop its collaborators, cached or not.
+	rr = httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/shared", strings.NewReader(`{"content": "after"}`)))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a shared note without force, got %v", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	s.handleNoteContent(rr, jsonRequest("PUT", "/api/notes/content?id=notes/shared&force=true", strings.NewReader(`{"content": "after"}`)))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected force to allow editing a shared note, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleRegistryLiveBypassesCache(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			w.Write([]byte(`{"notes": [{"name": "notes/live", "title": "Live"}]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+	s.statuses["notes/live"] = "Review"
+	expires := time.Now().Add(time.Minute).Truncate(time.Second)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/cached", Type: "keep", Title: "Cached"}}
+	s.registryCache.expiresAt = expires
+
+	ch := make(chan SSEMessage, 4)
+	s.clients[ch] = newSSEClient()
+
+	rr := httptest.NewRecorder()
+	s.handleRegistryLive(rr, httptest.NewRequest("GET", "/api/registry/live", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var items []workspace.RegistryItem
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "notes/live" || items[0].Status != "Review" {
+		t.Errorf("expected the live item with its status, got %+v", items)
+	}
+
+	if !s.registryCache.expiresAt.Equal(expires) {
+		t.Errorf("expected cache expiry to be unchanged, got %v", s.registryCache.expiresAt)
+	}
+	if len(s.registryCache.items) != 1 || s.registryCache.items[0].ID != "notes/cached" {
+		t.Errorf("expected cached items to be untouched, got %+v", s.registryCache.items)
+	}
+	if len(ch) != 0 {
+		t.Errorf("expected no broadcast, got %d messages", len(ch))
+	}
+}
+
+func TestHandleRegistryExportNDJSON(t *testing.T) {
+	s := setupTestServer(t)
+	s.statuses["notes/1"] = "Blocked"
+	s.annotations["notes/1"] = "waiting"
+	s.registryCache.items = []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep", Title: "One"},
+		{ID: "notes/2", Type: "keep", Title: "Two"},
+		{ID: "doc-1", Type: "doc", Title: "Doc"},
+	}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	rr := httptest.NewRecorder()
+	s.handleRegistryExport(rr, httptest.NewRequest("GET", "/api/registry/export.ndjson", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected application/x-ndjson, got %q", ct)
+	}
+	if !rr.Flushed {
+		t.Error("expected the response to be flushed while streaming")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), rr.Body.String())
+	}
+	var items []workspace.RegistryItem
+	for i, line := range lines {
+		var item workspace.RegistryItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v: %q", i, err, line)
+		}
+		items = append(items, item)
+	}
+	if items[0].ID != "notes/1" || items[0].Status != "Blocked" || items[0].Annotation != "waiting" {
+		t.Errorf("expected the first item enriched with status and annotation, got %+v", items[0])
+	}
+	if items[1].Status != "Pending" || items[2].Status != "" {
+		t.Errorf("expected default status for notes only, got %q and %q", items[1].Status, items[2].Status)
+	}
+}
+
+func TestHandleEventsSendsRetry(t *testing.T) {
+	s := setupTestServer(t)
+	s.sseRetry = 1500 * time.Millisecond
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		s.handleEvents(rr, httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx))
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	if !strings.HasPrefix(rr.Body.String(), "retry: 1500\n") {
+		t.Errorf("expected the stream to open with the retry hint, got %q", rr.Body.String())
+	}
+
+	s.maxSSEClients = 10
+	if got := s.sseRetryFor(1); got != 1950*time.Millisecond {
+		t.Errorf("expected a lightly loaded retry of 1.95s, got %v", got)
+	}
+	if got := s.sseRetryFor(10); got != 6*time.Second {
+		t.Errorf("expected the retry to reach 4x base at the cap, got %v", got)
+	}
+}
+
+func TestHandleNoteRawPassesThroughAttachments(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"name": "notes/raw",
+			"title": "Raw",
+			"createTime": "2026-01-01T00:00:00Z",
+			"attachments": [{"name": "notes/raw/attachments/a1", "mimeType": ["image/png"]}],
+			"permissions": [{"name": "notes/raw/permissions/p1", "email": "owner@example.com", "role": "OWNER"}]
+		}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleNoteRaw(rr, httptest.NewRequest("GET", "/api/notes/raw?id=notes/raw", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var note keep.Note
+	if err := json.NewDecoder(rr.Body).Decode(&note); err != nil {
+		t.Fatal(err)
+	}
+	if len(note.Attachments) != 1 || note.Attachments[0].Name != "notes/raw/attachments/a1" || note.Attachments[0].MimeType[0] != "image/png" {
+		t.Errorf("expected the attachment to survive, got %+v", note.Attachments)
+	}
+	if len(note.Permissions) != 1 || note.Permissions[0].Email != "owner@example.com" {
+		t.Errorf("expected the permission to survive, got %+v", note.Permissions)
+	}
+	if note.CreateTime != "2026-01-01T00:00:00Z" {
+		t.Errorf("expected the create time to survive, got %q", note.CreateTime)
+	}
+	if len(s.registryCache.items) != 0 {
+		t.Error("expected the raw endpoint not to touch the registry cache")
+	}
+}
+
+func TestHandleNoteDetailWithoutTitleOrBody(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes/bare" {
+			w.Write([]byte(`{"name": "notes/bare"}`))
+			return
+		}
+		w.Write([]byte(`{"notes": [{"name": "notes/bare"}], "files": []}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleNoteDetail(rr, httptest.NewRequest("GET", "/api/notes/detail?id=notes/bare", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 1 || items[0].Title != "Untitled" {
+		t.Errorf("expected the titleless note cached as Untitled, got %+v", items)
+	}
+}
+
+func TestNoteDetailSkipsBroadcastForUnchangedItem(t *testing.T) {
+	var title atomic.Value
+	title.Store("One")
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"name": "notes/1", "title": %q}`, title.Load())
+	}))
+	s.statuses["notes/1"] = "Active"
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One", Snippet: "Google Keep Note"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	ch := make(chan SSEMessage, 4)
+	s.clients[ch] = newSSEClient()
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		s.handleNoteDetail(rr, httptest.NewRequest("GET", "/api/notes/detail?id=notes/1", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+		}
+	}
+	if len(ch) != 0 {
+		t.Fatalf("expected no broadcast for an unchanged note, got %d messages", len(ch))
+	}
+
+	title.Store("Renamed")
+	s.forgetNote("notes/1") // skip the detail cache so the rename is fetched
+	s.handleNoteDetail(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/notes/detail?id=notes/1", nil))
+	if len(ch) != 1 {
+		t.Errorf("expected one broadcast after the title changed, got %d messages", len(ch))
+	}
+}
+
+func TestHandlerRefreshCanceledWithRequest(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(5 * time.Second):
+			t.Error("expected the upstream list to be canceled")
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.handleRegistry(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/registry", nil).WithContext(ctx))
+		close(done)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the upstream call to observe the cancellation")
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the handler to return after cancellation")
+	}
+	if !s.registryCache.expiresAt.IsZero() {
+		t.Error("expected a canceled refresh to leave the cache untouched")
+	}
+}
+
+func TestBodyLimitRejectsOversizedPost(t *testing.T) {
+	s := setupTestServer(t)
+	s.maxBodyBytes = 16
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}}
+	h := s.limitBody(http.HandlerFunc(s.handleStatus))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Active", strings.NewReader(strings.Repeat("x", 64))))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %v", rr.Code)
+	}
+	if _, ok := s.statuses["item-1"]; ok {
+		t.Error("expected the oversized request not to change status")
+	}
+
+	// Without a declared length the cap still applies once the body is read.
+	s.mode = "MANUAL"
+	req := jsonRequest("PUT", "/api/notes/content?id=notes/1", strings.NewReader(`{"content": "`+strings.Repeat("x", 64)+`"}`))
+	req.ContentLength = -1
+	rr = httptest.NewRecorder()
+	s.limitBody(http.HandlerFunc(s.handleNoteContent)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for an unbounded oversized body, got %v", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/api/status?id=item-1&status=Active", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected a bodiless POST to pass, got %v", rr.Code)
+	}
+}
+
+func TestScopeErrorGetsFriendlyMessage(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "Request had insufficient authentication scopes.",
+			"errors": [{"reason": "insufficientPermissions", "message": "Insufficient Permission"}]}}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sheets/detail?id=sheet-1", nil)
+	rr := httptest.NewRecorder()
+	s.handleGetSheet(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rr.Code)
+	}
+	if got := strings.TrimSpace(rr.Body.String()); got != msgMissingScope {
+		t.Errorf("expected the missing-scope message, got %q", got)
+	}
+}
+
+func TestRefreshTimesOutOnStuckFetch(t *testing.T) {
+	s := setupTestServer(t)
+	s.fetchTimeout = 50 * time.Millisecond
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+
+	start := time.Now()
+	err := s.refreshRegistryCache(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the refresh to give up at the timeout, took %v", elapsed)
+	}
+	if items, _ := s.cachedItemsFresh(); len(items) != 0 {
+		t.Errorf("expected the cache to be left untouched, got %+v", items)
+	}
+}
+
+func TestPausedCountdownDoesNotAdvance(t *testing.T) {
+	s := setupTestServer(t)
+	clk := newFakeClock()
+	s.clock = clk
+	ch := make(chan SSEMessage, 8)
+	s.clients[ch] = newSSEClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runPoller(ctx)
+	for deadline := time.Now().Add(time.Second); clk.tickerCount() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("poller never started its ticker")
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	s.handlePause(rr, httptest.NewRequest("POST", "/api/mode/pause", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if msg := <-ch; msg.Event != "state" || !strings.Contains(string(msg.Data), `"paused":true`) {
+		t.Fatalf("expected a paused state event, got %s %s", msg.Event, msg.Data)
+	}
+
+	for i := 0; i < 3; i++ {
+		clk.Advance(pollInterval)
+		time.Sleep(20 * time.Millisecond)
+	}
+	select {
+	case msg := <-ch:
+		t.Fatalf("expected no events while paused, got %s %s", msg.Event, msg.Data)
+	default:
+	}
+	if s.isManualMode() {
+		t.Fatal("expected pausing to leave AUTO mode in place")
+	}
+
+	s.handleResume(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/mode/resume", nil))
+	<-ch // resumed state event
+	clk.Advance(pollInterval)
+	select {
+	case msg := <-ch:
+		want := fmt.Sprintf(`{"seconds_remaining": %d}`, autoRefreshTicks-1)
+		if msg.Event != "tick" || string(msg.Data) != want {
+			t.Errorf("expected the countdown to resume at %s, got %s %s", want, msg.Event, msg.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a tick after resuming")
+	}
+}
+
+func TestHandleCountdownTracksPoller(t *testing.T) {
+	s := setupTestServer(t)
+	clk := newFakeClock()
+	s.clock = clk
+	ch := make(chan SSEMessage, 8)
+	s.clients[ch] = newSSEClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runPoller(ctx)
+	for deadline := time.Now().Add(time.Second); clk.tickerCount() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("poller never started its ticker")
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		clk.Advance(pollInterval)
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("expected a tick event")
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleCountdown(rr, httptest.NewRequest("GET", "/api/mode/countdown", nil))
+	var resp CountdownResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	want := CountdownResponse{Mode: "AUTO", SecondsRemaining: autoRefreshTicks - 3}
+	if resp != want {
+		t.Errorf("expected %+v, got %+v", want, resp)
+	}
+
+	s.handlePause(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/mode/pause", nil))
+	rr = httptest.NewRecorder()
+	s.handleCountdown(rr, httptest.NewRequest("GET", "/api/mode/countdown", nil))
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Paused || resp.SecondsRemaining != autoRefreshTicks-3 {
+		t.Errorf("expected a paused countdown at %d, got %+v", autoRefreshTicks-3, resp)
+	}
+}
+
+func TestNoteContentBatchPartialResults(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/notes/a":
+			w.Write([]byte(`{"name": "notes/a", "title": "A", "body": {"text": {"text": "alpha"}}}`))
+		case "/v1/notes/c":
+			w.Write([]byte(`{"name": "notes/c", "title": "C", "body": {"text": {"text": "gamma"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "Requested entity was not found."}}`))
+		}
+	}))
+	s.statuses["notes/a"] = "Blocked"
+
+	body := strings.NewReader(`["notes/a", "notes/bad", "notes/c"]`)
+	rr := httptest.NewRecorder()
+	s.handleNoteContentBatch(rr, jsonRequest("POST", "/api/notes/content-batch", body))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var results map[string]NoteContentResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]NoteContentResult{
+		"notes/a":   {Content: "alpha", Status: "Blocked"},
+		"notes/bad": {Error: msgNotFound},
+		"notes/c":   {Content: "gamma", Status: defaultItemStatus},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("expected %+v, got %+v", want, results)
+	}
+}
+
+func TestHandleGetDocMaxBytes(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"documentId": "doc-1", "title": "Big", "body": {"content": [
+			{"paragraph": {"elements": [{"textRun": {"content": "First line\n"}}]}},
+			{"paragraph": {"elements": [{"textRun": {"content": "Second line\n"}}]}}
+		]}}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleGetDoc(rr, httptest.NewRequest("GET", "/api/docs/detail?id=doc-1&maxBytes=5", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Content   string `json:"content"`
+		Truncated bool   `json:"truncated"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "First" || !resp.Truncated {
+		t.Errorf("expected truncated %q, got %+v", "First", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleGetDoc(rr, httptest.NewRequest("GET", "/api/docs/detail?id=doc-1&maxBytes=-1", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative maxBytes, got %v", rr.Code)
+	}
+}
+
+func TestPartialRefreshKeepsCachedItemsOfFailedSources(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			http.Error(w, `{"error": {"code": 403, "message": "denied"}}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+	s.registryCache.items = []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep", Title: "Kept"},
+		{ID: "doc-gone", Type: "doc", Title: "Deleted upstream"},
+	}
+	s.statuses["notes/1"] = "Active"
+
+	if err := s.refreshRegistryCache(context.Background()); err == nil {
+		t.Fatal("expected the Keep failure to be reported")
+	}
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 1 || items[0].ID != "notes/1" {
+		t.Fatalf("expected only the cached Keep note to survive, got %+v", items)
+	}
+	if s.statuses["notes/1"] != "Active" {
+		t.Errorf("expected the Keep note's status to be kept, got %q", s.statuses["notes/1"])
+	}
+}
+
+func TestPartialRefreshOnEmptyCacheKeepsStoredStatuses(t *testing.T) {
+	s := setupTestServer(t)
+	keepDown := true
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			if keepDown {
+				http.Error(w, `{"error": {"code": 403, "message": "denied"}}`, http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "Kept"}]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+	s.statuses["notes/1"] = "Active"
+	s.statuses["notes/gone"] = "Active"
+	s.db.SetStatus("notes/1", "Active")
+	s.db.SetStatus("notes/gone", "Active")
+
+	if err := s.refreshRegistryCache(context.Background()); err == nil {
+		t.Fatal("expected the Keep failure to be reported")
+	}
+	stored, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || len(s.statuses) != 2 {
+		t.Fatalf("expected stored statuses to survive the Keep outage, got db=%v memory=%v", stored, s.statuses)
+	}
+
+	// Once Keep lists again, startup reconciliation still runs.
+	keepDown = false
+	if err := s.refreshRegistryCache(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored["notes/gone"]; ok {
+		t.Errorf("expected the orphaned status to be reconciled away, got %v", stored)
+	}
+	if stored["notes/1"] != "Active" {
+		t.Errorf("expected the listed note's status to be kept, got %v", stored)
+	}
+}
+
+func TestWriteJSONReportsEncodeFailureCleanly(t *testing.T) {
+	s := setupTestServer(t)
+
+	rr := httptest.NewRecorder()
+	s.writeJSON(rr, http.StatusOK, struct {
+		Name   string   `json:"name"`
+		Notify chan int `json:"notify"`
+	}{Name: "x", Notify: make(chan int)})
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %v", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); strings.HasPrefix(ct, "application/json") {
+		t.Errorf("expected no JSON content type on failure, got %q", ct)
+	}
+	if strings.Contains(rr.Body.String(), `"name"`) {
+		t.Errorf("expected no partial JSON in the body, got %q", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	s.writeJSON(rr, http.StatusMultiStatus, map[string]int{"ok": 1})
+	if rr.Code != http.StatusMultiStatus || rr.Body.String() != "{\"ok\":1}\n" {
+		t.Errorf("expected 207 with the encoded body, got %v %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestWarmRegistryCache(t *testing.T) {
+	s, err := NewServer(nil, nil, Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+	if !s.warmCache {
+		t.Fatal("expected cache warming to be on by default")
+	}
+	s.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "Warm"}]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	}))
+
+	s.warmRegistryCache(context.Background())
+	items, fresh := s.cachedItemsFresh()
+	if !fresh || len(items) != 1 || items[0].ID != "notes/1" {
+		t.Fatalf("expected a fresh cache holding the note after warming, got %+v (fresh %v)", items, fresh)
+	}
+
+	cold, err := NewServer(nil, nil, Config{DataDir: t.TempDir(), SkipWarmCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cold.db.Close() })
+	if cold.warmCache {
+		t.Error("expected SkipWarmCache to turn warming off")
+	}
+}
+
+func TestWarmRegistryCacheFailureIsNotFatal(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"code": 403, "message": "denied"}}`, http.StatusForbidden)
+	}))
+
+	s.warmRegistryCache(context.Background())
+	if items, fresh := s.cachedItemsFresh(); fresh || len(items) != 0 {
+		t.Errorf("expected a cold cache after a failed warm, got %+v", items)
+	}
+}
+
+func TestJSONEndpointsRejectOtherContentTypes(t *testing.T) {
+	s := setupTestServer(t)
+	s.mode = "MANUAL"
+
+	for _, tc := range []struct {
+		contentType string
+		want        int
+	}{
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"", http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"application/json; charset=utf-8", http.StatusOK},
+	} {
+		req := httptest.NewRequest("POST", "/api/status/transition", strings.NewReader(`{"from": "Pending", "to": "Execute"}`))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		rr := httptest.NewRecorder()
+		s.handleStatusTransition(rr, req)
+		if rr.Code != tc.want {
+			t.Errorf("Content-Type %q: expected %d, got %d: %s", tc.contentType, tc.want, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleBatchDelete(rr, httptest.NewRequest("POST", "/api/notes/batch-delete", strings.NewReader(`["notes/1"]`)))
+	if rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for batch delete without a JSON content type, got %d", rr.Code)
+	}
+}
+
+func TestHandleSheetMeta(t *testing.T) {
+	var requests atomic.Int32
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v4/spreadsheets/sheet-1" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"spreadsheetId": "sheet-1",
+			"properties": {"title": "Budget"},
+			"sheets": [
+				{"properties": {"sheetId": 0, "title": "Data"}},
+				{"properties": {"sheetId": 5, "title": "Charts"},
+				 "charts": [{"chartId": 9, "spec": {"title": "Spend", "basicChart": {"chartType": "LINE"}}}]}
+			],
+			"namedRanges": [{"namedRangeId": "nr1", "name": "Totals",
+				"range": {"sheetId": 0, "startRowIndex": 0, "endRowIndex": 4, "startColumnIndex": 1, "endColumnIndex": 3}}]
+		}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleSheetMeta(rr, httptest.NewRequest(http.MethodGet, "/api/sheets/meta?id=sheet-1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var got SheetMetaResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Budget" || len(got.SheetTitles) != 2 || got.SheetTitles[1] != "Charts" {
+		t.Errorf("unexpected title or tabs: %+v", got)
+	}
+	if len(got.NamedRanges) != 1 || got.NamedRanges[0] != (workspace.NamedRange{Name: "Totals", Range: "Data!B1:C4"}) {
+		t.Errorf("unexpected named ranges: %+v", got.NamedRanges)
+	}
+	if len(got.Charts) != 1 || got.Charts[0] != (workspace.Chart{ID: 9, Title: "Spend", Type: "LINE", Sheet: "Charts"}) {
+		t.Errorf("unexpected charts: %+v", got.Charts)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected a single upstream read, got %d", n)
+	}
+}
+
+func TestCacheExpiryIsJittered(t *testing.T) {
+	rolls := []float64{0.25, 0.9}
+	clock := newFakeClock()
+	s := setupTestServer(t)
+	s.clock = clock
+	s.cacheJitter = 0.1
+	s.random = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": [], "files": []}`))
+	}))
+
+	var expiries []time.Time
+	for i := 0; i < 2; i++ {
+		if err := s.refreshRegistryCache(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		s.registryCache.mu.RLock()
+		expiries = append(expiries, s.registryCache.expiresAt)
+		s.registryCache.mu.RUnlock()
+	}
+
+	if expiries[0].Equal(expiries[1]) {
+		t.Fatalf("expected consecutive expiries to differ, both %v", expiries[0])
+	}
+	band := cacheTTL / 10
+	for i, at := range expiries {
+		if d := at.Sub(clock.Now()) - cacheTTL; d < -band || d > band {
+			t.Errorf("expiry %d is %v off the TTL, outside ±%v", i, d, band)
+		}
+	}
+	if want := clock.Now().Add(cacheTTL - band/2); !expiries[0].Equal(want) {
+		t.Errorf("expected a roll of 0.25 to expire at %v, got %v", want, expiries[0])
+	}
+}
+
+func TestHandleNoteSearch(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": [
+			{"name": "notes/a", "title": "Groceries", "body": {"text": {"text": "milk and eggs"}}},
+			{"name": "notes/b", "title": "Trip plan", "body": {"text": {"text": "book the train"}}},
+			{"name": "notes/c", "title": "Breakfast", "body": {"list": {"listItems": [{"text": {"text": "Eggs"}}]}}},
+			{"name": "notes/d", "title": "Milk run", "body": {"text": {"text": "corner shop"}}}
+		]}`))
+	}))
+
+	search := func(target string) []NoteSearchResult {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		s.handleNoteSearch(rr, httptest.NewRequest("GET", target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %v: %s", target, rr.Code, rr.Body.String())
+		}
+		var results []NoteSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	results := search("/api/notes/search?q=EGGS")
+	if len(results) != 2 || results[0].ID != "notes/a" || results[1].ID != "notes/c" {
+		t.Errorf("expected notes a and c to match eggs, got %+v", results)
+	}
+	if results[0].Body != "" {
+		t.Errorf("expected no body without includeBody, got %q", results[0].Body)
+	}
+
+	results = search("/api/notes/search?q=milk+eggs&includeBody=true")
+	if len(results) != 1 || results[0].ID != "notes/a" || results[0].Body != "milk and eggs" {
+		t.Errorf("expected only note a to match every term, with its body, got %+v", results)
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleNoteSearch(rr, httptest.NewRequest("GET", "/api/notes/search?q=milk&limit=1", nil))
+	if got := rr.Header().Get("X-Total-Count"); got != "2" {
+		t.Errorf("expected X-Total-Count 2, got %q", got)
+	}
+	if want := `</api/notes/search?limit=1&offset=1&q=milk>; rel="next"`; rr.Header().Get("Link") != want {
+		t.Errorf("expected Link %q, got %q", want, rr.Header().Get("Link"))
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleNoteSearch(rr, httptest.NewRequest("GET", "/api/notes/search", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without q, got %v", rr.Code)
+	}
+}
+
+func TestEmptyRegistryIsNotRefetched(t *testing.T) {
+	var lists atomic.Int32
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			lists.Add(1)
+		}
+		w.Write([]byte(`{"notes": [], "files": []}`))
+	}))
+	ch := make(chan SSEMessage, 4)
+	s.clients[ch] = newSSEClient()
+
+	if err := s.refreshRegistryCache(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.broadcastRegistry()
+	s.broadcastRegistry()
+	if items := s.currentRegistry(context.Background()); len(items) != 0 {
+		t.Errorf("expected an empty registry, got %+v", items)
+	}
+	data, ok := s.registrySnapshot(context.Background())
+	if !ok || string(data) != "[]" {
+		t.Errorf("expected an empty snapshot for a loaded empty registry, got %q (ok %v)", data, ok)
+	}
+
+	if n := lists.Load(); n != 1 {
+		t.Errorf("expected a single upstream listing, got %d", n)
+	}
+	if len(ch) != 2 {
+		t.Errorf("expected both broadcasts to go out, got %d messages", len(ch))
+	}
+}
+
+func TestHandleRegistryExportCSV(t *testing.T) {
+	s := setupTestServer(t)
+	s.statuses["notes/1"] = "Blocked"
+	s.registryCache.items = []workspace.RegistryItem{
+		{ID: "notes/1", Type: "keep", Title: "Milk, eggs", Snippet: `say "hi"`},
+		{ID: "notes/2", Type: "keep", Title: "Two"},
+		{ID: "doc-1", Type: "doc", Title: "Eggs benedict"},
+		{ID: "doc-2", Type: "doc", Title: `=HYPERLINK("http://evil.example","x")`, Snippet: "+cmd|' /C calc'!A0"},
+	}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	rr := httptest.NewRecorder()
+	s.handleRegistryExportCSV(rr, httptest.NewRequest("GET", "/api/registry/export.csv", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv, got %q", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="registry.csv"`) {
+		t.Errorf("expected a filename, got %q", cd)
+	}
+	if !rr.Flushed {
+		t.Error("expected the response to be flushed while streaming")
+	}
+	lines := strings.Split(rr.Body.String(), "\n")
+	if lines[0] != "id,type,title,status,snippet" {
+		t.Errorf("expected a header row, got %q", lines[0])
+	}
+	if want := `notes/1,keep,"Milk, eggs",Blocked,"say ""hi"""`; lines[1] != want {
+		t.Errorf("expected the comma to be quoted:\nwant %s\ngot  %s", want, lines[1])
+	}
+	rows, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := rows[len(rows)-1]; last[2] != `'=HYPERLINK("http://evil.example","x")` || last[4] != "'+cmd|' /C calc'!A0" {
+		t.Errorf("expected formula-like cells to be neutralized, got %q", last)
+	}
+	for _, cell := range []string{"-1", "@SUM(A1)", "\tx", "\rx"} {
+		if got := csvText(cell); got != "'"+cell {
+			t.Errorf("csvText(%q) = %q", cell, got)
+		}
+	}
+	if got := csvText("Milk, eggs"); got != "Milk, eggs" {
+		t.Errorf("expected plain text to pass through, got %q", got)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleRegistryExportCSV(rr, httptest.NewRequest("GET", "/api/registry/export.csv?type=keep&q=EGGS", nil))
+	rows, err = csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "notes/1" {
+		t.Errorf("expected only notes/1 to match both filters, got %v", rows)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleRegistry(rr, httptest.NewRequest("GET", "/api/registry?q=eggs", nil))
+	var items []workspace.RegistryItem
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Errorf("expected the JSON registry to apply the same filter, got %+v", items)
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleRegistryExportCSV(rr, httptest.NewRequest("GET", "/api/registry/export.csv?type=folder", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown type, got %v", rr.Code)
+	}
+}
+
+func TestRegistryExportCSVFailsOnColdRefreshError(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"code": 503, "message": "unavailable"}}`, http.StatusServiceUnavailable)
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleRegistryExportCSV(rr, httptest.NewRequest("GET", "/api/registry/export.csv", nil))
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 when the cold cache cannot be filled, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if strings.HasPrefix(rr.Body.String(), "id,type") {
+		t.Errorf("expected no CSV for a failed export, got %q", rr.Body.String())
+	}
+}
+
+func TestForcedRefreshIsThrottled(t *testing.T) {
+	var lists atomic.Int32
+	clock := newFakeClock()
+	s := setupTestServer(t)
+	s.clock = clock
+	s.mode = "MANUAL"
+	s.minRefresh = 2 * time.Second
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			lists.Add(1)
+		}
+		w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "One"}], "files": []}`))
+	}))
+
+	refresh := func() *httptest.ResponseRecorder {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		s.handleRegistry(rr, httptest.NewRequest("GET", "/api/registry?refresh=true", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+		}
+		return rr
+	}
+
+	if rr := refresh(); rr.Header().Get(throttledHeader) != "" {
+		t.Error("expected the first forced refresh to go through")
+	}
+	clock.Advance(time.Second)
+	rr := refresh()
+	if rr.Header().Get(throttledHeader) != "true" {
+		t.Error("expected the second forced refresh to be throttled")
+	}
+	if !strings.Contains(rr.Body.String(), "notes/1") {
+		t.Errorf("expected the throttled request to be served from cache, got %s", rr.Body.String())
+	}
+	if n := lists.Load(); n != 1 {
+		t.Fatalf("expected one upstream fetch for two rapid refreshes, got %d", n)
+	}
+
+	clock.Advance(time.Second)
+	if rr := refresh(); rr.Header().Get(throttledHeader) != "" {
+		t.Error("expected a refresh after the interval to go through")
+	}
+	if n := lists.Load(); n != 2 {
+		t.Errorf("expected a second upstream fetch after the interval, got %d", n)
+	}
+
+	s.minRefresh = 0
+	if rr := refresh(); rr.Header().Get(throttledHeader) != "" {
+		t.Error("expected a zero interval to disable throttling")
+	}
+	if n := lists.Load(); n != 3 {
+		t.Errorf("expected an immediate upstream fetch with throttling off, got %d", n)
+	}
+}
+
+func TestGetFormWithFormsDisabled(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s", r.URL.Path)
+	}))
+
+	rr := httptest.NewRecorder()
+	s.handleGetForm(rr, httptest.NewRequest(http.MethodGet, "/api/forms/detail?id=form-1", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 while forms support is disabled, got %v: %s", rr.Code, rr.Body.String())
+	}
+}
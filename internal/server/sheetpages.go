@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/sheetpages.go
+Description: Paginated sheet reads. A range is read from Sheets once, held
+briefly, and served in row windows so a very large sheet never has to be sent
+in a single response.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sheetPageTTL is how long a read range is reused for further pages.
+	sheetPageTTL = time.Minute
+
+	defaultSheetPageRange = "A:Z"
+	defaultSheetPageSize  = 100
+	maxSheetPageSize      = 1000
+)
+
+// SheetPageResponse is one window of rows from a sheet range.
+type SheetPageResponse struct {
+	Page      int             `json:"page"`
+	Size      int             `json:"size"`
+	TotalRows int             `json:"totalRows"`
+	Values    [][]interface{} `json:"values"`
+}
+
+// sheetValuesCache holds recently read ranges, keyed by sheet ID and range.
+type sheetValuesCache struct {
+	mu      sync.Mutex
+	entries map[string]sheetValuesEntry
+}
+
+type sheetValuesEntry struct {
+	rows    [][]interface{}
+	expires time.Time
+}
+
+// sheetRows returns the rows of readRange, reading it from Sheets unless a
+// recent read is cached. The read is bounded by the fetch timeout and ends
+// early if ctx is canceled.
+func (s *Server) sheetRows(ctx context.Context, id, readRange string) ([][]interface{}, error) {
+	key := id + "\x00" + readRange
+	now := s.now()
+
+	s.sheetValues.mu.Lock()
+	if e, ok := s.sheetValues.entries[key]; ok && now.Before(e.expires) {
+		s.sheetValues.mu.Unlock()
+		return e.rows, nil
+	}
+	s.sheetValues.mu.Unlock()
+
+	ctx, cancel := s.withFetchTimeout(ctx)
+	defer cancel()
+	resp, err := s.workspace().GetSheetValues(ctx, id, readRange, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.sheetValues.mu.Lock()
+	defer s.sheetValues.mu.Unlock()
+	if s.sheetValues.entries == nil {
+		s.sheetValues.entries = make(map[string]sheetValuesEntry)
+	}
+	for k, e := range s.sheetValues.entries {
+		if !now.Before(e.expires) {
+			delete(s.sheetValues.entries, k)
+		}
+	}
+	s.sheetValues.entries[key] = sheetValuesEntry{rows: resp.Values, expires: now.Add(sheetPageTTL)}
+	return resp.Values, nil
+}
+
+// forgetSheetValues drops every cached range of sheet id.
+func (s *Server) forgetSheetValues(id string) {
+	prefix := id + "\x00"
+	s.sheetValues.mu.Lock()
+	defer s.sheetValues.mu.Unlock()
+	for k := range s.sheetValues.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(s.sheetValues.entries, k)
+		}
+	}
+}
+
+// clearSheetValues drops every cached range.
+func (s *Server) clearSheetValues() {
+	s.sheetValues.mu.Lock()
+	s.sheetValues.entries = nil
+	s.sheetValues.mu.Unlock()
+}
+
+// handleSheetValuesPage serves one page of rows (?page=, 1-based, and ?size=)
+// from ?range= of sheet ?id=. The total row count is sent in X-Total-Count.
+func (s *Server) handleSheetValuesPage(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	readRange := strings.TrimSpace(r.URL.Query().Get("range"))
+	if readRange == "" {
+		readRange = defaultSheetPageRange
+	}
+	page, err := parsePageParam(r, "page", 1)
+	if err != nil || page < 1 {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+	size, err := parsePageParam(r, "size", defaultSheetPageSize)
+	if err != nil || size < 1 || size > maxSheetPageSize {
+		http.Error(w, fmt.Sprintf("invalid size; want 1 to %d", maxSheetPageSize), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := s.sheetRows(r.Context(), id, readRange)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+
+	total := len(rows)
+	start := min((page-1)*size, total)
+	end := min(start+size, total)
+	window := rows[start:end]
+	if window == nil {
+		window = [][]interface{}{}
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	s.writeJSON(w, http.StatusOK, SheetPageResponse{Page: page, Size: size, TotalRows: total, Values: window})
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/sheetpages_test.go
+Description: Unit tests for paginated sheet value reads.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHandleSheetValuesPage(t *testing.T) {
+	var reads atomic.Int32
+	clock := newFakeClock()
+	s := setupTestServer(t)
+	s.clock = clock
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v4/spreadsheets/sheet-1/values/") {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		reads.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"range": "Sheet1!A1:B5", "values": [["r1"], ["r2"], ["r3"], ["r4"], ["r5"]]}`))
+	}))
+
+	get := func(url string) (*httptest.ResponseRecorder, SheetPageResponse) {
+		rr := httptest.NewRecorder()
+		s.handleSheetValuesPage(rr, httptest.NewRequest("GET", url, nil))
+		var resp SheetPageResponse
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rr, resp
+	}
+
+	rr, resp := get("/api/sheets/values/page?id=sheet-1&page=2&size=2")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if resp.TotalRows != 5 || rr.Header().Get("X-Total-Count") != "5" {
+		t.Errorf("expected 5 total rows, got %d (header %q)", resp.TotalRows, rr.Header().Get("X-Total-Count"))
+	}
+	if len(resp.Values) != 2 || resp.Values[0][0] != "r3" || resp.Values[1][0] != "r4" {
+		t.Errorf("expected rows r3 and r4, got %v", resp.Values)
+	}
+
+	_, resp = get("/api/sheets/values/page?id=sheet-1&page=3&size=2")
+	if len(resp.Values) != 1 || resp.Values[0][0] != "r5" {
+		t.Errorf("expected the last page to hold r5, got %v", resp.Values)
+	}
+	_, resp = get("/api/sheets/values/page?id=sheet-1&page=4&size=2")
+	if resp.Values == nil || len(resp.Values) != 0 {
+		t.Errorf("expected an empty page past the end, got %v", resp.Values)
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("expected later pages to reuse the cached read, got %d reads", n)
+	}
+
+	clock.Advance(sheetPageTTL)
+	get("/api/sheets/values/page?id=sheet-1&page=1&size=2")
+	if n := reads.Load(); n != 2 {
+		t.Errorf("expected an expired read to be refetched, got %d reads", n)
+	}
+
+	// Deleting the sheet or switching subject must not serve rows read before.
+	s.markPendingDelete("sheet-1")
+	get("/api/sheets/values/page?id=sheet-1&page=1&size=2")
+	if n := reads.Load(); n != 3 {
+		t.Errorf("expected a delete to drop the cached read, got %d reads", n)
+	}
+	s.clearSheetValues()
+	get("/api/sheets/values/page?id=sheet-1&page=1&size=2")
+	if n := reads.Load(); n != 4 {
+		t.Errorf("expected a cleared cache to be refetched, got %d reads", n)
+	}
+
+	for _, bad := range []string{"page=0", "size=0", "size=5000", "page=x"} {
+		if rr, _ := get("/api/sheets/values/page?id=sheet-1&" + bad); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", bad, rr.Code)
+		}
+	}
+}
+
+func TestSheetValuesPageHonorsRequestContext(t *testing.T) {
+	var reads atomic.Int32
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"values": [["r1"]]}`))
+	}))
+
+	// A client that is already gone must not cost a Sheets read.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rr := httptest.NewRecorder()
+	s.handleSheetValuesPage(rr, httptest.NewRequest("GET", "/api/sheets/values/page?id=sheet-1", nil).WithContext(ctx))
+	if rr.Code == http.StatusOK {
+		t.Error("expected the canceled read to fail")
+	}
+	if n := reads.Load(); n != 0 {
+		t.Errorf("expected no Sheets read for a canceled request, got %d", n)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/snippets.go
+Description: Content snippets for docs and sheets. Previews are fetched during full
+refreshes with bounded concurrency and cached until the file's modified time changes.
+*/
+package server
+
+import (
+	"context"
+	"sync"
+
+	"axis/internal/workspace"
+)
+
+// snippetWorkers bounds concurrent content fetches during a refresh.
+const snippetWorkers = 4
+
+// snippetCache remembers previews by item ID, keyed on the modified time they
+// were built from.
+type snippetCache struct {
+	mu      sync.Mutex
+	entries map[string]snippetEntry
+}
+
+type snippetEntry struct {
+	modified string
+	snippet  string
+}
+
+// fillSnippets replaces the generic snippets of doc and sheet items with content
+// previews, fetching only items that are new or modified since the last refresh.
+// Items whose fetch fails keep the generic snippet and are retried next time.
+func (s *Server) fillSnippets(ctx context.Context, items []workspace.RegistryItem) {
+	s.snippets.mu.Lock()
+	if s.snippets.entries == nil {
+		s.snippets.entries = make(map[string]snippetEntry)
+	}
+	var stale []int
+	live := make(map[string]bool, len(items))
+	for i, item := range items {
+		if item.Type != "doc" && item.Type != "sheet" {
+			continue
+		}
+		live[item.ID] = true
+		if e, ok := s.snippets.entries[item.ID]; ok && e.modified == item.ModifiedTime && item.ModifiedTime != "" {
+			items[i].Snippet = e.snippet
+			continue
+		}
+		stale = append(stale, i)
+	}
+	for id := range s.snippets.entries {
+		if !live[id] {
+			delete(s.snippets.entries, id)
+		}
+	}
+	s.snippets.mu.Unlock()
+
+	ws := s.workspace()
+	sem := make(chan struct{}, snippetWorkers)
+	var wg sync.WaitGroup
+	for _, i := range stale {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item *workspace.RegistryItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			snippet, err := ws.FetchSnippet(ctx, *item)
+			if err != nil {
+				s.logger.Warn("snippet fetch failed", "id", item.ID, "type", item.Type, "error", err)
+				return
+			}
+			item.Snippet = snippet
+			s.snippets.mu.Lock()
+			s.snippets.entries[item.ID] = snippetEntry{modified: item.ModifiedTime, snippet: snippet}
+			s.snippets.mu.Unlock()
+		}(&items[i])
+	}
+	wg.Wait()
+}
+
+// clearSnippets drops every cached preview. The map is replaced rather than
+// nilled because fetches still in flight write into it.
+func (s *Server) clearSnippets() {
+	s.snippets.mu.Lock()
+	s.snippets.entries = make(map[string]snippetEntry)
+	s.snippets.mu.Unlock()
+}
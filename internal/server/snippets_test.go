@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/snippets_test.go
+Description: Unit tests for fetching and caching doc and sheet snippets.
+*/
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRefreshFillsDocSnippetFromFirstLine(t *testing.T) {
+	var docFetches atomic.Int32
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		case r.URL.Path == "/v1/documents/doc-1":
+			docFetches.Add(1)
+			w.Write([]byte(`{"documentId": "doc-1", "body": {"content": [
+				{"paragraph": {"elements": [{"textRun": {"content": "Launch checklist\n"}}]}},
+				{"paragraph": {"elements": [{"textRun": {"content": "More detail\n"}}]}}
+			]}}`))
+		case strings.Contains(r.URL.Query().Get("q"), "document"):
+			w.Write([]byte(`{"files": [{"id": "doc-1", "name": "Doc", "modifiedTime": "2026-02-01T00:00:00Z"}]}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+
+	ctx := context.Background()
+	s.refreshRegistryCache(ctx)
+	items, _ := s.cachedItemsFresh()
+	if len(items) != 1 || items[0].Snippet != "Launch checklist" {
+		t.Fatalf("expected the doc's first line as its snippet, got %+v", items)
+	}
+
+	s.refreshRegistryCache(ctx)
+	if n := docFetches.Load(); n != 1 {
+		t.Errorf("expected an unmodified doc to reuse its cached snippet, got %d fetches", n)
+	}
+	items, _ = s.cachedItemsFresh()
+	if items[0].Snippet != "Launch checklist" {
+		t.Errorf("expected the cached snippet after a second refresh, got %q", items[0].Snippet)
+	}
+}
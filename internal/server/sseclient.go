@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/sseclient.go
+Description: SSE client bookkeeping. Tracks each client's event filter, resync
+token, and diagnostics, and reaps stale clients: half-open connections never fire
+their request context, so clients whose channel stays full are dropped on a timer.
+*/
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	clientReapInterval = 15 * time.Second
+	staleClientTimeout = time.Minute
+)
+
+// sseClient tracks one event stream connection.
+type sseClient struct {
+	// fullSince is when the channel was first found full; zero while draining.
+	fullSince time.Time
+	// done is closed when the client is reaped so its handler returns.
+	done chan struct{}
+	// events limits delivery to the named event types; nil means every type.
+	events map[string]bool
+	// token identifies the connection to /api/events/resync.
+	token string
+	// resync asks the handler to resend the full registry snapshot.
+	resync chan struct{}
+
+	// Diagnostics for /api/events/clients. dropped is guarded by clientsMu.
+	id          string
+	remoteAddr  string
+	connectedAt time.Time
+	dropped     int
+}
+
+func newSSEClient() *sseClient {
+	return &sseClient{
+		done:   make(chan struct{}),
+		token:  rand.Text(),
+		resync: make(chan struct{}, 1),
+	}
+}
+
+// requestResync queues a snapshot resend for the client whose token matches,
+// reporting whether such a client is connected.
+func (s *Server) requestResync(token string) bool {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for _, client := range s.clients {
+		if client.token == token {
+			select {
+			case client.resync <- struct{}{}:
+			default: // a resync is already queued
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// SSEClientInfo describes one connected event stream client.
+type SSEClientInfo struct {
+	ID          string    `json:"id"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	Events      []string  `json:"events,omitempty"`
+	Dropped     int       `json:"dropped"`
+}
+
+// handleEventsClients lists the connected event stream clients, oldest first,
+// for diagnosing clients that stop receiving updates.
+func (s *Server) handleEventsClients(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	s.clientsMu.Lock()
+	infos := make([]SSEClientInfo, 0, len(s.clients))
+	for _, client := range s.clients {
+		info := SSEClientInfo{
+			ID:          client.id,
+			RemoteAddr:  client.remoteAddr,
+			ConnectedAt: client.connectedAt,
+			Dropped:     client.dropped,
+		}
+		for event := range client.events {
+			info.Events = append(info.Events, event)
+		}
+		sort.Strings(info.Events)
+		infos = append(infos, info)
+	}
+	s.clientsMu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].ConnectedAt.Equal(infos[j].ConnectedAt) {
+			return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+
+	s.writeJSON(w, http.StatusOK, infos)
+}
+
+// handleEventsResync resends the full registry to one event stream client,
+// identified by the token from its "connected" event. Clients use it when the
+// initial snapshot never arrived.
+func (s *Server) handleEventsResync(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "missing token", http.StatusBadRequest)
+		return
+	}
+	if !s.requestResync(token) {
+		http.Error(w, "no event stream with that token", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// registryEvent names the unnamed full registry snapshot message for filtering.
+const registryEvent = "registry"
+
+// parseEventFilter turns a comma-separated ?events= value into a filter set,
+// returning nil (every event) when it names nothing.
+func parseEventFilter(raw string) map[string]bool {
+	var events map[string]bool
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if events == nil {
+				events = make(map[string]bool)
+			}
+			events[name] = true
+		}
+	}
+	return events
+}
+
+// wants reports whether the client subscribed to event. Heartbeats always pass
+// so filtered clients are still probed for liveness.
+func (c *sseClient) wants(event string) bool {
+	if c.events == nil || event == "heartbeat" {
+		return true
+	}
+	if event == "" {
+		event = registryEvent
+	}
+	return c.events[event]
+}
+
+// runClientReaper periodically reaps stale event stream clients.
+func (s *Server) runClientReaper(ctx context.Context) {
+	ticker := s.newTicker(clientReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C():
+			if n := s.reapStaleClients(now); n > 0 {
+				s.logger.Info("reaped stale SSE clients", "count", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reapStaleClients sends each client a non-blocking heartbeat and removes those
+// whose channel has stayed full for longer than staleClientTimeout. It returns
+// the number of clients removed.
+func (s *Server) reapStaleClients(now time.Time) int {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	reaped := 0
+	for ch, client := range s.clients {
+		select {
+		case ch <- SSEMessage{Event: "heartbeat", Data: []byte(now.UTC().Format(time.RFC3339))}:
+			client.fullSince = time.Time{}
+			continue
+		default:
+		}
+		if client.fullSince.IsZero() {
+			client.fullSince = now
+			continue
+		}
+		if now.Sub(client.fullSince) > staleClientTimeout {
+			delete(s.clients, ch)
+			close(client.done)
+			reaped++
+		}
+	}
+	return reaped
+}
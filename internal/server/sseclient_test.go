@@ -0,0 +1,311 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/sseclient_test.go
+Description: Unit tests for SSE client event filters and stale client reaping.
+*/
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestReapStaleClients(t *testing.T) {
+	s := setupTestServer(t)
+
+	stuck := make(chan SSEMessage, 1)
+	stuck <- SSEMessage{Data: []byte("undrained")}
+	stuckClient := newSSEClient()
+	s.clients[stuck] = stuckClient
+
+	healthy := make(chan SSEMessage, 1)
+	s.clients[healthy] = newSSEClient()
+
+	now := time.Now()
+	if n := s.reapStaleClients(now); n != 0 {
+		t.Fatalf("expected no reaping on first sighting, got %d", n)
+	}
+	<-healthy // the healthy client drains its heartbeat
+
+	if n := s.reapStaleClients(now.Add(staleClientTimeout + time.Second)); n != 1 {
+		t.Fatalf("expected 1 client reaped, got %d", n)
+	}
+	if _, ok := s.clients[stuck]; ok {
+		t.Error("expected the undrained client to be removed")
+	}
+	if _, ok := s.clients[healthy]; !ok {
+		t.Error("expected the draining client to remain")
+	}
+	select {
+	case <-stuckClient.done:
+	default:
+		t.Error("expected the reaped client to be closed out")
+	}
+}
+
+func TestHandleEventsFilter(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		s.handleEvents(rr, httptest.NewRequest("GET", "/api/events?events=status", nil).WithContext(ctx))
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		s.clientsMu.Lock()
+		n := len(s.clients)
+		s.clientsMu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client never registered")
+		}
+	}
+
+	s.broadcastTick(5)
+	s.broadcastStatusChange("notes/1", "", "Blocked", "One")
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rr.Body.String()
+	if strings.Contains(body, "event: tick") {
+		t.Errorf("expected ticks to be filtered out, got %q", body)
+	}
+	if !strings.Contains(body, "event: status") {
+		t.Errorf("expected the status event to be delivered, got %q", body)
+	}
+	if strings.Contains(body, "data: [") {
+		t.Errorf("expected no registry snapshot, got %q", body)
+	}
+}
+
+func TestEventsResyncRecoversMissedSnapshot(t *testing.T) {
+	s := setupTestServer(t)
+	// Upstream is down at connect time, so the initial snapshot is never sent.
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleEvents))
+	defer ts.Close()
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the event stream")
+			return ""
+		}
+	}
+
+	var token string
+	for token == "" {
+		if line := next(); strings.HasPrefix(line, "data: {\"token\"") {
+			var hello struct{ Token string }
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &hello); err != nil {
+				t.Fatal(err)
+			}
+			token = hello.Token
+		}
+	}
+
+	s.registryCache.mu.Lock()
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "Recovered"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+	s.registryCache.mu.Unlock()
+
+	rr := httptest.NewRecorder()
+	s.handleEventsResync(rr, httptest.NewRequest("POST", "/api/events/resync?token="+token, nil))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for {
+		line := next()
+		if strings.HasPrefix(line, "data: [") {
+			if !strings.Contains(line, "Recovered") {
+				t.Errorf("expected the resynced snapshot, got %q", line)
+			}
+			break
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleEventsResync(rr, httptest.NewRequest("POST", "/api/events/resync?token=unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown token, got %d", rr.Code)
+	}
+}
+
+func TestEventsClientsListing(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.handleEvents(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/events?events=tick,status", nil).WithContext(ctx))
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var infos []SSEClientInfo
+	for deadline := time.Now().Add(time.Second); len(infos) == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("client never appeared in the listing")
+		}
+		rr := httptest.NewRecorder()
+		s.handleEventsClients(rr, httptest.NewRequest("GET", "/api/events/clients", nil))
+		if err := json.NewDecoder(rr.Body).Decode(&infos); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := infos[0]
+	if len(infos) != 1 || got.ID == "" || got.RemoteAddr != "192.0.2.1:1234" || got.ConnectedAt.IsZero() {
+		t.Fatalf("unexpected client listing: %+v", infos)
+	}
+	if len(got.Events) != 2 || got.Events[0] != "status" || got.Events[1] != "tick" {
+		t.Errorf("expected the sorted event filter, got %v", got.Events)
+	}
+}
+
+func TestEventsConnectDisconnectDuringBroadcasts(t *testing.T) {
+	s := setupTestServer(t)
+	// A stale cache sends each new client's snapshot only after a refresh attempt,
+	// which fails once the client is gone, so snapshots land after disconnect.
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			s.broadcastTick(i)
+			s.broadcastStatusChange("notes/1", "", "Active", "One")
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		s.handleEvents(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/events", nil).WithContext(ctx))
+		cancel()
+	}
+	// Give trailing snapshot sends time to run against the disconnected clients.
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	if len(s.clients) != 0 {
+		t.Errorf("expected every client to be removed, %d remain", len(s.clients))
+	}
+}
+
+// stalledWriter is a streaming ResponseWriter whose writes block until release
+// is closed, standing in for a client on a slow link.
+type stalledWriter struct {
+	header  http.Header
+	release chan struct{}
+}
+
+func (w *stalledWriter) Header() http.Header { return w.header }
+func (w *stalledWriter) WriteHeader(int)     {}
+func (w *stalledWriter) Flush()              {}
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestSSEBufferAbsorbsBurst(t *testing.T) {
+	const burst = 25
+	for _, tc := range []struct {
+		buffer      int
+		wantDropped int
+	}{
+		{0, burst - defaultSSEBuffer}, // the default buffer loses the overflow
+		{32, 0},
+	} {
+		s := setupTestServer(t)
+		s.sseBuffer = Config{SSEBuffer: tc.buffer}.withDefaults().SSEBuffer
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &stalledWriter{header: make(http.Header), release: make(chan struct{})}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.handleEvents(w, httptest.NewRequest("GET", "/api/events?events=status", nil).WithContext(ctx))
+		}()
+		for deadline := time.Now().Add(time.Second); ; {
+			s.clientsMu.Lock()
+			n := len(s.clients)
+			s.clientsMu.Unlock()
+			if n == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("client never registered")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		for i := 0; i < burst; i++ {
+			s.broadcast(SSEMessage{Event: "status", Data: []byte("{}")})
+		}
+		s.clientsMu.Lock()
+		dropped := s.droppedEvents
+		s.clientsMu.Unlock()
+		if dropped != tc.wantDropped {
+			t.Errorf("buffer %d: expected %d dropped events, got %d", tc.buffer, tc.wantDropped, dropped)
+		}
+
+		cancel()
+		close(w.release)
+		<-done
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/timeout.go
+Description: Client-supplied request deadlines. Honors the X-Request-Timeout
+header on read handlers that may refresh from Google, answering 504 when the
+deadline passes before the handler finishes.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTimeoutHeader carries the client's deadline in milliseconds.
+const requestTimeoutHeader = "X-Request-Timeout"
+
+// withRequestTimeout bounds next by the X-Request-Timeout header, if present.
+// The handler writes to a buffer; whichever finishes first, the handler or the
+// deadline, decides the response. GET/read handlers only: an abandoned handler
+// keeps running, so a mutation cut off midway would be reported as failed while
+// it carries on. Other methods pass through without a deadline. Not for
+// streaming handlers.
+func (s *Server) withRequestTimeout(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := strings.TrimSpace(r.Header.Get(requestTimeoutHeader))
+		if raw == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next(w, r)
+			return
+		}
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms <= 0 {
+			http.Error(w, "invalid "+requestTimeoutHeader+"; want positive milliseconds", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(ms)*time.Millisecond)
+		defer cancel()
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			next(tw, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		// A handler that returns after the deadline most likely gave up on its
+		// upstream call, so its response is treated as a timeout too.
+		if ctx.Err() == nil {
+			tw.copyTo(w)
+			return
+		}
+		tw.abandon()
+		if r.Context().Err() != nil {
+			return // the client went away; nobody is listening
+		}
+		s.logger.Warn("request deadline exceeded", "path", r.URL.Path, "timeoutMs", ms)
+		http.Error(w, "request timed out", http.StatusGatewayTimeout)
+	}
+}
+
+// timeoutWriter buffers a handler's response until withRequestTimeout decides
+// whether to send it. Writes after the deadline are discarded.
+type timeoutWriter struct {
+	mu        sync.Mutex
+	header    http.Header
+	buf       bytes.Buffer
+	code      int
+	abandoned bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.code == 0 {
+		tw.code = code
+	}
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.abandoned {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) abandon() {
+	tw.mu.Lock()
+	tw.abandoned = true
+	tw.mu.Unlock()
+}
+
+// copyTo sends the buffered response. Only called once the handler returned.
+func (tw *timeoutWriter) copyTo(w http.ResponseWriter) {
+	for k, v := range tw.header {
+		w.Header()[k] = v
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	w.Write(tw.buf.Bytes())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/timeout_test.go
+Description: Unit tests for the X-Request-Timeout deadline wrapper.
+*/
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestRequestTimeoutReturns504(t *testing.T) {
+	s := setupTestServer(t)
+	s.ws = newStubWorkspace(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	handler := s.withRequestTimeout(s.handleRegistry)
+
+	req := httptest.NewRequest("GET", "/api/registry", nil)
+	req.Header.Set(requestTimeoutHeader, "20")
+	rr := httptest.NewRecorder()
+	start := time.Now()
+	handler(rr, req)
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the deadline to cut the request short, took %v", elapsed)
+	}
+
+	req = httptest.NewRequest("GET", "/api/registry", nil)
+	req.Header.Set(requestTimeoutHeader, "soon")
+	rr = httptest.NewRecorder()
+	handler(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed header, got %v", rr.Code)
+	}
+}
+
+func TestRequestTimeoutPassesFastResponses(t *testing.T) {
+	s := setupTestServer(t)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "notes/1", Type: "keep", Title: "One"}}
+	s.registryCache.expiresAt = time.Now().Add(time.Minute)
+
+	req := httptest.NewRequest("GET", "/api/registry", nil)
+	req.Header.Set(requestTimeoutHeader, "2000")
+	rr := httptest.NewRecorder()
+	s.withRequestTimeout(s.handleRegistry)(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected the handler's headers to be copied, got %q", ct)
+	}
+	if !strings.Contains(rr.Body.String(), `"notes/1"`) {
+		t.Errorf("expected the registry in the body, got %s", rr.Body.String())
+	}
+}
+
+func TestRequestTimeoutIgnoresMutations(t *testing.T) {
+	s := setupTestServer(t)
+	release := make(chan struct{})
+	handler := s.withRequestTimeout(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("expected no deadline on a PUT")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("PUT", "/api/notes/content?id=notes/1", nil)
+	req.Header.Set(requestTimeoutHeader, "1")
+	rr := httptest.NewRecorder()
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	handler(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected the handler's own response, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/vacuum.go
+Description: SQLite compaction. Periodically VACUUMs the state database to
+reclaim pages left behind by status churn, and exposes a manual trigger.
+*/
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const defaultVacuumInterval = 24 * time.Hour
+
+// VacuumResponse reports the database size around a vacuum.
+type VacuumResponse struct {
+	BeforeBytes int64 `json:"beforeBytes"`
+	AfterBytes  int64 `json:"afterBytes"`
+}
+
+// vacuumDB compacts the database. It holds persistMu so it never overlaps a
+// state flush or snapshot.
+func (s *Server) vacuumDB() (VacuumResponse, error) {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	var resp VacuumResponse
+	start := s.now()
+	before, err := s.db.Size()
+	if err != nil {
+		return resp, err
+	}
+	if err := s.db.Vacuum(); err != nil {
+		return resp, err
+	}
+	after, err := s.db.Size()
+	if err != nil {
+		return resp, err
+	}
+	resp.BeforeBytes, resp.AfterBytes = before, after
+	s.logger.Info("database vacuumed", "beforeBytes", before, "afterBytes", after, "duration", s.now().Sub(start))
+	return resp, nil
+}
+
+// runVacuum compacts the database every vacuumEvery until ctx is canceled.
+func (s *Server) runVacuum(ctx context.Context) {
+	interval := s.vacuumEvery
+	if interval <= 0 {
+		interval = defaultVacuumInterval
+	}
+	ticker := s.newTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if _, err := s.vacuumDB(); err != nil {
+				s.logger.Error("database vacuum failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleStateVacuum compacts the database on demand.
+func (s *Server) handleStateVacuum(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if !s.isManualMode() {
+		http.Error(w, "state vacuum requires MANUAL mode", http.StatusForbidden)
+		return
+	}
+
+	resp, err := s.vacuumDB()
+	if err != nil {
+		s.logger.Error("database vacuum failed", "error", err)
+		http.Error(w, "vacuum failed", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/vacuum_test.go
+Description: Unit tests for database compaction.
+*/
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestManualVacuumOnPopulatedDB(t *testing.T) {
+	s := setupTestServer(t)
+	annotation := strings.Repeat("x", 400)
+	for i := 0; i < 500; i++ {
+		id := fmt.Sprintf("notes/%d", i)
+		if err := s.db.SetStatusAnnotation(id, "Active", annotation); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 450; i++ {
+		if err := s.db.DeleteStatus(fmt.Sprintf("notes/%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	s.handleStateVacuum(rr, httptest.NewRequest("POST", "/api/state/vacuum", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 in AUTO mode, got %v", rr.Code)
+	}
+
+	s.mode = "MANUAL"
+	rr = httptest.NewRecorder()
+	s.handleStateVacuum(rr, httptest.NewRequest("POST", "/api/state/vacuum", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var resp VacuumResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.AfterBytes <= 0 || resp.AfterBytes >= resp.BeforeBytes {
+		t.Errorf("expected the vacuum to shrink the file, got %d -> %d bytes", resp.BeforeBytes, resp.AfterBytes)
+	}
+
+	statuses, err := s.db.GetStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 50 {
+		t.Errorf("expected the remaining 50 statuses to survive, got %d", len(statuses))
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/webhook.go
+Description: Outbound webhook dispatcher for status transitions. Posts a compact
+JSON payload to an operator-configured URL (Slack, Zapier, etc.) with a bounded
+timeout and retries, without blocking the request that changed the status.
+*/
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	webhookTimeout    = 5 * time.Second
+	webhookRetries    = 2
+	webhookRetryDelay = 500 * time.Millisecond
+)
+
+// StatusChangeEvent is the payload delivered to the status webhook.
+type StatusChangeEvent struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// webhookNotifier posts status change events to a single URL.
+type webhookNotifier struct {
+	url        string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+	logger     *slog.Logger
+}
+
+// newWebhookNotifier returns nil when no URL is configured, which disables delivery.
+func newWebhookNotifier(url string, logger *slog.Logger) *webhookNotifier {
+	if url == "" {
+		return nil
+	}
+	return &webhookNotifier{
+		url:        url,
+		client:     &http.Client{Timeout: webhookTimeout},
+		retries:    webhookRetries,
+		retryDelay: webhookRetryDelay,
+		logger:     logger,
+	}
+}
+
+// Notify delivers the event in the background. It is safe to call on a nil notifier.
+func (n *webhookNotifier) Notify(evt StatusChangeEvent) {
+	if n == nil {
+		return
+	}
+	go func() {
+		if err := n.send(evt); err != nil {
+			n.logger.Error("status webhook delivery failed", "id", evt.ID, "error", err)
+		}
+	}()
+}
+
+// send posts the event, retrying on transport errors and non-2xx responses.
+func (n *webhookNotifier) send(evt StatusChangeEvent) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(n.retryDelay)
+		}
+
+		resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return lastErr
+}
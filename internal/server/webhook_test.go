@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/server/webhook_test.go
+Description: Unit tests for the status change webhook dispatcher, covering payload
+shape, retry behavior, and delivery from the status endpoint.
+*/
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+func TestWebhookPayloadShape(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json, got %s", ct)
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	s := setupTestServer(t)
+	s.webhook = newWebhookNotifier(receiver.URL, s.logger)
+	s.registryCache.items = []workspace.RegistryItem{{ID: "item-1", Title: "Test Item"}}
+	s.statuses["item-1"] = "Pending"
+
+	req := httptest.NewRequest("POST", "/api/status?id=item-1&status=Active", nil)
+	rr := httptest.NewRecorder()
+	s.handleStatus(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", rr.Code)
+	}
+
+	select {
+	case payload := <-received:
+		for key, want := range map[string]string{"id": "item-1", "title": "Test Item", "from": "Pending", "to": "Active"} {
+			if payload[key] != want {
+				t.Errorf("expected %s=%q, got %v", key, want, payload[key])
+			}
+		}
+		ts, ok := payload["timestamp"].(string)
+		if !ok {
+			t.Fatalf("expected timestamp string, got %v", payload["timestamp"])
+		}
+		if _, err := time.Parse(time.RFC3339, ts); err != nil {
+			t.Errorf("expected RFC3339 timestamp, got %q", ts)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestWebhookRetriesOnFailure(t *testing.T) {
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	n := newWebhookNotifier(receiver.URL, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	n.retryDelay = time.Millisecond
+
+	if err := n.send(StatusChangeEvent{ID: "item-1", To: "Active"}); err != nil {
+		t.Fatalf("expected delivery after retry, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestWebhookDisabledWithoutURL(t *testing.T) {
+	n := newWebhookNotifier("", nil)
+	if n != nil {
+		t.Fatal("expected nil notifier when no URL is configured")
+	}
+	// Notify on a nil notifier must be a no-op.
+	n.Notify(StatusChangeEvent{ID: "item-1"})
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/auth.go
+Description: Domain-Wide Delegation bootstrap. Builds token sources and Google API
+clients that impersonate a given subject through the service account, and caches
+the resulting Service per subject so the acting user can be switched at runtime.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	admin "google.golang.org/api/admin/directory/v1"
+	chat "google.golang.org/api/chat/v1"
+	docs "google.golang.org/api/docs/v1"
+	drive "google.golang.org/api/drive/v3"
+	forms "google.golang.org/api/forms/v1"
+	gmail "google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/impersonate"
+	keep "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// DelegatedScopes are requested on behalf of the impersonated subject and must
+// match the scopes granted to the service account in the DWD console.
+var DelegatedScopes = []string{
+	admin.AdminDirectoryUserReadonlyScope,
+	keep.KeepScope,
+	docs.DocumentsScope,
+	sheets.SpreadsheetsScope,
+	// Full Drive scope: registry deletes go through Drive, which readonly refuses.
+	drive.DriveScope,
+	gmail.GmailModifyScope,
+	"https://www.googleapis.com/auth/chat.spaces.create",
+}
+
+// FormsScope is requested on top of DelegatedScopes only when forms support is
+// enabled, so grants that predate it keep working.
+const FormsScope = forms.FormsBodyReadonlyScope
+
+// BotScopes are requested when acting as the Chat app itself rather than a user.
+var BotScopes = []string{
+	"https://www.googleapis.com/auth/chat.bot",
+	"https://www.googleapis.com/auth/chat.messages.create",
+	"https://www.googleapis.com/auth/chat.spaces.create",
+}
+
+// NewDelegatedService builds a Service whose clients impersonate subject via the
+// service account. Chat messages are still sent with the app's own identity.
+// withForms also requests FormsScope. Delegation refuses the whole token
+// exchange when one requested scope is not granted, so if the grant lacks it
+// the Service is built without Forms instead; FormsEnabled reports which.
+func NewDelegatedService(ctx context.Context, serviceAccountEmail, subject string, withForms bool) (*Service, error) {
+	scopes := DelegatedScopes
+	if withForms {
+		scopes = append(slices.Clone(DelegatedScopes), FormsScope)
+	}
+	ts, err := delegatedTokenSource(ctx, serviceAccountEmail, subject, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if withForms {
+		if _, err := ts.Token(); err != nil {
+			base, baseErr := delegatedTokenSource(ctx, serviceAccountEmail, subject, DelegatedScopes)
+			if baseErr != nil {
+				return nil, baseErr
+			}
+			// Only drop Forms if the grant works without it; otherwise keep the
+			// failure for the scope check to report.
+			if _, baseErr := base.Token(); baseErr == nil {
+				ts, scopes, withForms = base, DelegatedScopes, false
+			}
+		}
+	}
+
+	// No Subject here: the bot authenticates as the application itself.
+	botTs, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: serviceAccountEmail,
+		Scopes:          BotScopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot token source: %w", err)
+	}
+
+	userOpt := option.WithTokenSource(ts)
+
+	adminSvc, err := admin.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Admin service: %w", err)
+	}
+	keepSvc, err := keep.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Keep service: %w", err)
+	}
+	docsSvc, err := docs.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docs service: %w", err)
+	}
+	sheetsSvc, err := sheets.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
+	}
+	driveSvc, err := drive.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Drive service: %w", err)
+	}
+	gmailSvc, err := gmail.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gmail service: %w", err)
+	}
+	var formsSvc *forms.Service
+	if withForms {
+		if formsSvc, err = forms.NewService(ctx, userOpt); err != nil {
+			return nil, fmt.Errorf("failed to create Forms service: %w", err)
+		}
+	}
+	chatUserSvc, err := chat.NewService(ctx, userOpt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Chat User service: %w", err)
+	}
+	chatBotSvc, err := chat.NewService(ctx, option.WithTokenSource(botTs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Chat Bot service: %w", err)
+	}
+
+	svc := NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, gmailSvc, chatUserSvc, chatBotSvc, formsSvc)
+	svc.tokenSource = ts
+	svc.scopes = scopes
+	return svc, nil
+}
+
+// delegatedTokenSource returns a token source impersonating subject with scopes.
+func delegatedTokenSource(ctx context.Context, serviceAccountEmail, subject string, scopes []string) (oauth2.TokenSource, error) {
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: serviceAccountEmail,
+		Subject:         subject,
+		Scopes:          scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token source for %s: %w", subject, err)
+	}
+	return ts, nil
+}
+
+// scopeProbeID is a file id that never exists. Probing it costs one cheap
+// request and yields a 404 when the scope is granted, a 403 when it is not.
+const scopeProbeID = "axis-scope-check"
+
+// scopeCheck is one trivial call that only succeeds if scope was granted.
+type scopeCheck struct {
+	service string
+	scope   string
+	call    func(ctx context.Context) error
+}
+
+// scopeChecks lists a probe for every configured client the registry relies on.
+func (s *Service) scopeChecks() []scopeCheck {
+	var checks []scopeCheck
+	if s.keepService != nil {
+		checks = append(checks, scopeCheck{"Keep", keep.KeepScope, func(ctx context.Context) error {
+			_, err := s.keepService.Notes.List().PageSize(1).Context(ctx).Do()
+			return err
+		}})
+	}
+	if s.driveService != nil {
+		// An update, unlike a list, is refused under the readonly scope.
+		checks = append(checks, scopeCheck{"Drive", drive.DriveScope, func(ctx context.Context) error {
+			_, err := s.driveService.Files.Update(scopeProbeID, &drive.File{}).Fields("id").Context(ctx).Do()
+			return err
+		}})
+	}
+	if s.docsService != nil {
+		checks = append(checks, scopeCheck{"Docs", docs.DocumentsScope, func(ctx context.Context) error {
+			_, err := s.docsService.Documents.Get(scopeProbeID).Context(ctx).Do()
+			return err
+		}})
+	}
+	if s.sheetsService != nil {
+		checks = append(checks, scopeCheck{"Sheets", sheets.SpreadsheetsScope, func(ctx context.Context) error {
+			_, err := s.sheetsService.Spreadsheets.Get(scopeProbeID).Context(ctx).Do()
+			return err
+		}})
+	}
+	return checks
+}
+
+// CheckScopes verifies the delegation grant. It first exchanges a token on its
+// own: delegation refuses the whole exchange when any requested scope is not
+// granted, which would otherwise fail every probe alike. It then makes one
+// trivial call per service and reports every service Google refuses as missing
+// its scope. Probes that never got an answer (a timeout, an outage, rate
+// limiting) are reported as unverified rather than as missing scopes, and any
+// other API error shows the call got through (e.g. the probe id not existing),
+// so the scope works.
+func (s *Service) CheckScopes(ctx context.Context) error {
+	if s.tokenSource != nil {
+		if _, err := s.tokenSource.Token(); err != nil {
+			return exchangeRefused(s.scopes, err)
+		}
+	}
+
+	var errs []error
+	for _, check := range s.scopeChecks() {
+		err := check.call(ctx)
+		if err == nil {
+			continue
+		}
+		var retrieveErr *oauth2.RetrieveError
+		var apiErr *googleapi.Error
+		switch kind := KindOf(err); {
+		case errors.As(err, &retrieveErr):
+			return exchangeRefused(s.scopes, err)
+		case kind == KindPermissionDenied:
+			errs = append(errs, fmt.Errorf("missing scope %s for service %s: %w", check.scope, check.service, err))
+		case kind == KindTransient || kind == KindRateLimited || !errors.As(err, &apiErr):
+			errs = append(errs, fmt.Errorf("could not verify scope %s for service %s: %w", check.scope, check.service, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// exchangeRefused reports a token exchange the delegation grant turned down.
+func exchangeRefused(scopes []string, err error) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("delegated token exchange refused; check that the grant includes every requested scope: %w", err)
+	}
+	return fmt.Errorf("delegated token exchange refused; check that the grant includes every requested scope (%s): %w", strings.Join(scopes, ", "), err)
+}
+
+// ServiceFactory builds a Service acting as the given subject.
+type ServiceFactory func(ctx context.Context, subject string) (*Service, error)
+
+// ServicePool lazily builds and caches one Service per impersonated subject.
+type ServicePool struct {
+	factory  ServiceFactory
+	services map[string]*Service
+	mu       sync.Mutex
+}
+
+// NewServicePool creates a pool that uses factory to build services on demand.
+func NewServicePool(factory ServiceFactory) *ServicePool {
+	return &ServicePool{
+		factory:  factory,
+		services: make(map[string]*Service),
+	}
+}
+
+// Get returns the cached Service for subject, building it on first use.
+func (p *ServicePool) Get(ctx context.Context, subject string) (*Service, error) {
+	key := strings.ToLower(strings.TrimSpace(subject))
+	if key == "" {
+		return nil, fmt.Errorf("subject must not be empty")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if svc, ok := p.services[key]; ok {
+		return svc, nil
+	}
+	svc, err := p.factory(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	p.services[key] = svc
+	return svc, nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/auth_test.go
+Description: Unit tests for the per-subject service pool used for runtime
+impersonation and the startup scope check.
+*/
+package workspace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+	docs "google.golang.org/api/docs/v1"
+	drive "google.golang.org/api/drive/v3"
+	keep "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestServicePoolPerSubject(t *testing.T) {
+	built := map[string]int{}
+	pool := NewServicePool(func(ctx context.Context, subject string) (*Service, error) {
+		built[subject]++
+		return NewService(nil, &keep.Service{}, nil, nil, nil, nil, nil, nil, nil), nil
+	})
+
+	ctx := context.Background()
+	alice, err := pool.Get(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := pool.Get(ctx, "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alice == bob {
+		t.Fatal("expected distinct services for distinct subjects")
+	}
+	if alice.keepService == bob.keepService {
+		t.Error("expected distinct underlying clients for distinct subjects")
+	}
+
+	again, err := pool.Get(ctx, " Alice@Example.com ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != alice {
+		t.Error("expected the cached service to be reused for the same subject")
+	}
+	if built["alice@example.com"] != 1 || built["bob@example.com"] != 1 {
+		t.Errorf("expected one build per subject, got %v", built)
+	}
+
+	if _, err := pool.Get(ctx, ""); err == nil {
+		t.Error("expected an error for an empty subject")
+	}
+}
+
+func TestCheckScopesReportsMissingScope(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"code": 403, "message": "Request had insufficient authentication scopes.", "errors": [{"reason": "insufficientPermissions"}]}}`))
+		case r.URL.Path == "/files":
+			w.Write([]byte(`{"files": []}`))
+		default:
+			// Docs and Sheets probes hit an id that does not exist.
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "Requested entity was not found."}}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+	docsSvc, _ := docs.NewService(ctx, opts...)
+	sheetsSvc, _ := sheets.NewService(ctx, opts...)
+	ws := NewService(nil, keepSvc, docsSvc, sheetsSvc, driveSvc, nil, nil, nil, nil)
+
+	err := ws.CheckScopes(ctx)
+	if err == nil {
+		t.Fatal("expected the missing Keep scope to be reported")
+	}
+	want := "missing scope " + keep.KeepScope + " for service Keep"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q in the error, got: %v", want, err)
+	}
+	for _, other := range []string{"Drive", "Docs", "Sheets"} {
+		if strings.Contains(err.Error(), "for service "+other) {
+			t.Errorf("expected %s to pass the check, got: %v", other, err)
+		}
+	}
+}
+
+// failedExchange fails every request the way a token source does when the
+// delegation grant does not authorize the requested scope.
+type failedExchange struct{}
+
+func (failedExchange) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, &oauth2.RetrieveError{ErrorCode: "unauthorized_client", ErrorDescription: "Client is unauthorized to retrieve access tokens using this method"}
+}
+
+func TestCheckScopesReportsRejectedTokenExchange(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": []}`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	keepSvc, _ := keep.NewService(ctx, option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	driveSvc, _ := drive.NewService(ctx, option.WithEndpoint(ts.URL), option.WithHTTPClient(&http.Client{Transport: failedExchange{}}))
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+
+	err := ws.CheckScopes(ctx)
+	if err == nil {
+		t.Fatal("expected the rejected token exchange to fail the check")
+	}
+	if !strings.Contains(err.Error(), "token exchange refused") {
+		t.Errorf("expected the refused exchange to be reported, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "missing scope") {
+		t.Errorf("expected no per-service scope blame for a refused exchange, got: %v", err)
+	}
+}
+
+// refusedSource is a token source whose exchange the delegation grant refuses.
+type refusedSource struct{}
+
+func (refusedSource) Token() (*oauth2.Token, error) {
+	return nil, &oauth2.RetrieveError{ErrorCode: "unauthorized_client"}
+}
+
+func TestCheckScopesChecksTokenExchangeFirst(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no probes after a refused exchange, got %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	keepSvc, _ := keep.NewService(ctx, option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	ws := NewService(nil, keepSvc, nil, nil, nil, nil, nil, nil, nil)
+	ws.tokenSource = refusedSource{}
+	ws.scopes = []string{keep.KeepScope}
+
+	err := ws.CheckScopes(ctx)
+	if err == nil || !strings.Contains(err.Error(), "token exchange refused") || !strings.Contains(err.Error(), keep.KeepScope) {
+		t.Fatalf("expected a refused exchange listing the requested scopes, got: %v", err)
+	}
+}
+
+func TestCheckScopesSeparatesOutagesFromMissingScopes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": 503, "message": "Backend Error"}}`))
+		case r.Method == http.MethodPatch:
+			// The readonly Drive scope lists files but cannot update them.
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"code": 403, "message": "Request had insufficient authentication scopes.", "errors": [{"reason": "insufficientPermissions"}]}}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+
+	err := ws.CheckScopes(ctx)
+	if err == nil {
+		t.Fatal("expected the check to fail")
+	}
+	if want := "missing scope " + drive.DriveScope + " for service Drive"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q in the error, got: %v", want, err)
+	}
+	if want := "could not verify scope " + keep.KeepScope + " for service Keep"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q in the error, got: %v", want, err)
+	}
+	if strings.Contains(err.Error(), "missing scope "+keep.KeepScope) {
+		t.Errorf("expected the Keep outage not to be blamed on a scope, got: %v", err)
+	}
+}
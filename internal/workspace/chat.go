@@ -33,7 +33,7 @@ func (s *Service) SendDirectMessage(email string, text string) error {
 
 	space, err := s.chatUserSvc.Spaces.Setup(req).Do()
 	if err != nil {
-		return fmt.Errorf("failed to setup chat space for %s: %w", email, err)
+		return apiErrorf(err, "failed to setup chat space for %s", email)
 	}
 
 	// 2. Send the message to the established space using App (Bot) Auth
@@ -43,7 +43,7 @@ func (s *Service) SendDirectMessage(email string, text string) error {
 
 	_, err = s.chatBotSvc.Spaces.Messages.Create(space.Name, msg).Do()
 	if err != nil {
-		return fmt.Errorf("failed to send chat message to %s: %w", email, err)
+		return apiErrorf(err, "failed to send chat message to %s", email)
 	}
 
 	return nil
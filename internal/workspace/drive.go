@@ -0,0 +1,286 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/drive.go
+Description: Google Drive helpers. Scopes file listing and deletes to include
+shared drives, registers and stops push channels on the Drive Changes feed so the
+server can react to edits instead of waiting for the next poll, checks the feed
+for pending changes, and reads document comments and revisions.
+*/
+package workspace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+var errDriveUnavailable = errors.New("google drive service is not configured")
+
+// SetSharedDrive restricts Drive listing and the changes feed to one shared
+// drive. An empty ID searches everything the impersonated user can see,
+// including shared drive items.
+func (s *Service) SetSharedDrive(driveID string) {
+	s.sharedDriveID = driveID
+}
+
+// SetOwnedOnly limits Drive listing to files the impersonated user owns, hiding
+// files shared in by others. Shared drive files have no individual owner, so
+// they are hidden too.
+func (s *Service) SetOwnedOnly(owned bool) {
+	s.ownedOnly = owned
+}
+
+// hidesDriveFile reports whether a listed file is filtered out of the registry.
+func (s *Service) hidesDriveFile(f *drive.File) bool {
+	return s.exclusions.excludes(f) || (s.ownedOnly && !f.OwnedByMe)
+}
+
+// listDriveFiles starts a Files.List call for q that includes shared drive items.
+func (s *Service) listDriveFiles(q string) *drive.FilesListCall {
+	if s.ownedOnly {
+		q += " and 'me' in owners"
+	}
+	call := s.driveService.Files.List().Q(q).SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+	if s.sharedDriveID != "" {
+		call = call.Corpora("drive").DriveId(s.sharedDriveID)
+	}
+	return call
+}
+
+// deleteDriveFile deletes a file, which may live on a shared drive.
+func (s *Service) deleteDriveFile(fileID string) error {
+	return s.driveService.Files.Delete(fileID).SupportsAllDrives(true).Do()
+}
+
+// DriveChannel identifies an active Drive push notification channel.
+type DriveChannel struct {
+	ID         string
+	ResourceID string
+	Token      string
+	Expiration time.Time
+}
+
+// WatchDriveChanges registers a web_hook channel that delivers notifications for
+// any change visible to the impersonated user. token is echoed back by Google in
+// the X-Goog-Channel-Token header so the receiver can authenticate deliveries.
+func (s *Service) WatchDriveChanges(ctx context.Context, address, token string, ttl time.Duration) (*DriveChannel, error) {
+	if s.driveService == nil {
+		return nil, errDriveUnavailable
+	}
+
+	start, err := s.DriveStartPageToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newChannelID()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &drive.Channel{
+		Id:         id,
+		Type:       "web_hook",
+		Address:    address,
+		Token:      token,
+		Expiration: time.Now().Add(ttl).UnixMilli(),
+	}
+	call := s.driveService.Changes.Watch(start, req).SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+	if s.sharedDriveID != "" {
+		call = call.DriveId(s.sharedDriveID)
+	}
+	ch, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, apiErrorf(err, "unable to watch drive changes")
+	}
+
+	expiration := time.Now().Add(ttl)
+	if ch.Expiration > 0 {
+		expiration = time.UnixMilli(ch.Expiration)
+	}
+	return &DriveChannel{
+		ID:         ch.Id,
+		ResourceID: ch.ResourceId,
+		Token:      token,
+		Expiration: expiration,
+	}, nil
+}
+
+// StopDriveChannel stops delivery on a previously registered channel.
+func (s *Service) StopDriveChannel(ctx context.Context, ch *DriveChannel) error {
+	if ch == nil {
+		return nil
+	}
+	if s.driveService == nil {
+		return errDriveUnavailable
+	}
+	err := s.driveService.Channels.Stop(&drive.Channel{Id: ch.ID, ResourceId: ch.ResourceID}).Context(ctx).Do()
+	if err != nil {
+		return apiErrorf(err, "unable to stop drive channel %s", ch.ID)
+	}
+	return nil
+}
+
+// DriveStartPageToken returns the Drive changes token for the current state.
+func (s *Service) DriveStartPageToken(ctx context.Context) (string, error) {
+	if s.driveService == nil {
+		return "", errDriveUnavailable
+	}
+	call := s.driveService.Changes.GetStartPageToken().SupportsAllDrives(true)
+	if s.sharedDriveID != "" {
+		call = call.DriveId(s.sharedDriveID)
+	}
+	start, err := call.Context(ctx).Do()
+	if err != nil {
+		return "", apiErrorf(err, "unable to get drive start page token")
+	}
+	return start.StartPageToken, nil
+}
+
+// DriveChangedSince reports whether any Drive change has been recorded since
+// token, along with the token to use for the next check.
+func (s *Service) DriveChangedSince(ctx context.Context, token string) (bool, string, error) {
+	if s.driveService == nil {
+		return false, "", errDriveUnavailable
+	}
+	changed := false
+	for {
+		call := s.driveService.Changes.List(token).Fields("nextPageToken,newStartPageToken,changes(fileId)").
+			SupportsAllDrives(true).IncludeItemsFromAllDrives(true)
+		if s.sharedDriveID != "" {
+			call = call.DriveId(s.sharedDriveID)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return false, "", apiErrorf(err, "unable to list drive changes")
+		}
+		if len(resp.Changes) > 0 {
+			changed = true
+		}
+		if resp.NextPageToken == "" {
+			return changed, resp.NewStartPageToken, nil
+		}
+		token = resp.NextPageToken
+	}
+}
+
+func newChannelID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("unable to generate channel id: %w", err)
+	}
+	return "axis-" + hex.EncodeToString(buf), nil
+}
+
+// DocComment is a simplified Drive comment on a document.
+type DocComment struct {
+	ID          string `json:"id"`
+	Author      string `json:"author"`
+	Content     string `json:"content"`
+	Resolved    bool   `json:"resolved"`
+	CreatedTime string `json:"createdTime"`
+}
+
+// ListDocComments returns every comment on a document, following pagination.
+func (s *Service) ListDocComments(ctx context.Context, docID string) ([]DocComment, error) {
+	if s.driveService == nil {
+		return nil, errDriveUnavailable
+	}
+
+	var comments []DocComment
+	pageToken := ""
+	for {
+		call := s.driveService.Comments.List(docID).
+			Fields("nextPageToken", "comments(id,author(displayName,emailAddress),content,resolved,createdTime)").
+			PageSize(100).
+			Context(ctx)
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, apiErrorf(err, "unable to list comments for doc %s", docID)
+		}
+
+		for _, c := range resp.Comments {
+			author := ""
+			if c.Author != nil {
+				author = c.Author.DisplayName
+				if author == "" {
+					author = c.Author.EmailAddress
+				}
+			}
+			comments = append(comments, DocComment{
+				ID:          c.Id,
+				Author:      author,
+				Content:     c.Content,
+				Resolved:    c.Resolved,
+				CreatedTime: c.CreatedTime,
+			})
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return comments, nil
+}
+
+// DocRevision is a simplified Drive revision of a document.
+type DocRevision struct {
+	ID           string `json:"id"`
+	ModifiedTime string `json:"modifiedTime"`
+	ModifiedBy   string `json:"modifiedBy"`
+}
+
+// ListDocRevisions returns a document's revisions, oldest first, following pagination.
+func (s *Service) ListDocRevisions(ctx context.Context, docID string) ([]DocRevision, error) {
+	if s.driveService == nil {
+		return nil, errDriveUnavailable
+	}
+
+	var revisions []DocRevision
+	pageToken := ""
+	for {
+		call := s.driveService.Revisions.List(docID).
+			Fields("nextPageToken", "revisions(id,modifiedTime,lastModifyingUser(displayName,emailAddress))").
+			PageSize(200).
+			Context(ctx)
+		if pageToken != "" {
+			call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, apiErrorf(err, "unable to list revisions for doc %s", docID)
+		}
+
+		for _, rev := range resp.Revisions {
+			by := ""
+			if rev.LastModifyingUser != nil {
+				by = rev.LastModifyingUser.DisplayName
+				if by == "" {
+					by = rev.LastModifyingUser.EmailAddress
+				}
+			}
+			revisions = append(revisions, DocRevision{
+				ID:           rev.Id,
+				ModifiedTime: rev.ModifiedTime,
+				ModifiedBy:   by,
+			})
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return revisions, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/drive_test.go
+Description: Unit tests for Drive helpers: document comments, revisions, and change channels.
+*/
+package workspace
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListDocComments(t *testing.T) {
+	ws := newTestService(t, testDrive, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/doc-1/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"nextPageToken": "p2", "comments": [
+				{"id": "c1", "author": {"displayName": "Ada"}, "content": "Fix this", "resolved": false, "createdTime": "2026-01-02T03:04:05Z"}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"comments": [
+			{"id": "c2", "author": {"emailAddress": "bob@example.com"}, "content": "Done", "resolved": true, "createdTime": "2026-01-03T00:00:00Z"}
+		]}`))
+	})
+
+	comments, err := ws.ListDocComments(context.Background(), "doc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(comments))
+	}
+
+	first := comments[0]
+	if first.ID != "c1" || first.Author != "Ada" || first.Content != "Fix this" || first.Resolved || first.CreatedTime != "2026-01-02T03:04:05Z" {
+		t.Errorf("unexpected first comment: %+v", first)
+	}
+	second := comments[1]
+	if second.ID != "c2" || second.Author != "bob@example.com" || !second.Resolved {
+		t.Errorf("unexpected second comment: %+v", second)
+	}
+}
+
+func TestDriveRequestsIncludeSharedDrives(t *testing.T) {
+	var lists, deletes int
+	ws := newTestService(t, testDrive, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("supportsAllDrives") != "true" {
+			t.Errorf("%s %s: expected supportsAllDrives=true", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/files":
+			lists++
+			if q.Get("includeItemsFromAllDrives") != "true" {
+				t.Error("expected includeItemsFromAllDrives=true on list")
+			}
+			if q.Get("corpora") != "drive" || q.Get("driveId") != "team-drive" {
+				t.Errorf("expected the shared drive corpus, got corpora=%q driveId=%q", q.Get("corpora"), q.Get("driveId"))
+			}
+			w.Write([]byte(`{"files": []}`))
+		case r.Method == http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	ws.SetSharedDrive("team-drive")
+
+	if _, err := ws.ListDriveRegistryItems(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.DeleteDoc("doc-1"); err != nil {
+		t.Fatal(err)
+	}
+	if lists != 3 || deletes != 1 {
+		t.Errorf("expected 3 lists and 1 delete, got %d and %d", lists, deletes)
+	}
+}
+
+func TestListDocRevisions(t *testing.T) {
+	ws := newTestService(t, testDrive, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/doc-1/revisions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"nextPageToken": "p2", "revisions": [
+				{"id": "1", "modifiedTime": "2026-01-01T09:00:00Z", "lastModifyingUser": {"displayName": "Ada"}}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"revisions": [
+			{"id": "7", "modifiedTime": "2026-02-01T10:00:00Z", "lastModifyingUser": {"emailAddress": "bob@example.com"}}
+		]}`))
+	})
+
+	revisions, err := ws.ListDocRevisions(context.Background(), "doc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DocRevision{
+		{ID: "1", ModifiedTime: "2026-01-01T09:00:00Z", ModifiedBy: "Ada"},
+		{ID: "7", ModifiedTime: "2026-02-01T10:00:00Z", ModifiedBy: "bob@example.com"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("expected %d revisions, got %+v", len(want), revisions)
+	}
+	for i := range want {
+		if revisions[i] != want[i] {
+			t.Errorf("revision %d: expected %+v, got %+v", i, want[i], revisions[i])
+		}
+	}
+}
+
+func TestOwnedOnlyFiltersSharedInFiles(t *testing.T) {
+	ws := newTestService(t, testDrive, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		if !strings.HasSuffix(q, " and 'me' in owners") {
+			t.Errorf("expected the owners clause in the query, got %q", q)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(q, "document") {
+			w.Write([]byte(`{"files": [
+				{"id": "mine", "name": "Mine", "ownedByMe": true},
+				{"id": "theirs", "name": "Theirs", "ownedByMe": false}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"files": []}`))
+	})
+	ws.SetOwnedOnly(true)
+
+	items, err := ws.ListDriveRegistryItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "mine" {
+		t.Errorf("expected only the owned file, got %+v", items)
+	}
+}
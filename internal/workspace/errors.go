@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/errors.go
+Description: Typed errors for Google API failures. Classifies googleapi errors
+into a small set of kinds so callers can react without matching strings.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrorKind classifies why a workspace operation failed.
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindNotFound
+	KindPermissionDenied
+	KindRateLimited
+	KindTransient
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindPermissionDenied:
+		return "permission denied"
+	case KindRateLimited:
+		return "rate limited"
+	case KindTransient:
+		return "transient"
+	default:
+		return "unknown"
+	}
+}
+
+// WorkspaceError is returned by Service methods when a Google API call fails.
+// It wraps the original error, so googleapi details remain reachable.
+type WorkspaceError struct {
+	Kind ErrorKind
+	// Op describes the failed operation, e.g. "unable to get note notes/1".
+	Op  string
+	Err error
+}
+
+func (e *WorkspaceError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *WorkspaceError) Unwrap() error {
+	return e.Err
+}
+
+// apiErrorf wraps err from a Google API call in a classified WorkspaceError.
+func apiErrorf(err error, format string, args ...any) error {
+	return &WorkspaceError{Kind: classifyError(err), Op: fmt.Sprintf(format, args...), Err: err}
+}
+
+// PartialListError reports registry sources that failed while others succeeded.
+// Types names the item types whose source failed; Err is the first failure.
+type PartialListError struct {
+	Types []string
+	Err   error
+}
+
+func (e *PartialListError) Error() string {
+	return fmt.Sprintf("registry listing missing %s: %v", strings.Join(e.Types, ", "), e.Err)
+}
+
+func (e *PartialListError) Unwrap() error {
+	return e.Err
+}
+
+// KindOf reports the kind of err. Errors that are not WorkspaceErrors are
+// classified directly, so raw googleapi errors work too.
+func KindOf(err error) ErrorKind {
+	var wsErr *WorkspaceError
+	if errors.As(err, &wsErr) {
+		return wsErr.Kind
+	}
+	return classifyError(err)
+}
+
+func classifyError(err error) ErrorKind {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests || HasErrorReason(apiErr, "rateLimitExceeded", "userRateLimitExceeded"):
+			return KindRateLimited
+		case apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone:
+			return KindNotFound
+		case apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden:
+			return KindPermissionDenied
+		case apiErr.Code >= 500:
+			return KindTransient
+		}
+		return KindUnknown
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return KindTransient
+	}
+	return KindUnknown
+}
+
+// HasErrorReason reports whether apiErr carries any of reasons.
+func HasErrorReason(apiErr *googleapi.Error, reasons ...string) bool {
+	for _, item := range apiErr.Errors {
+		for _, reason := range reasons {
+			if item.Reason == reason {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/errors_test.go
+Description: Unit tests for WorkspaceError classification.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestClassifyGoogleAPIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"not found", &googleapi.Error{Code: 404}, KindNotFound},
+		{"gone", &googleapi.Error{Code: 410}, KindNotFound},
+		{"forbidden", &googleapi.Error{Code: 403}, KindPermissionDenied},
+		{"unauthorized", &googleapi.Error{Code: 401}, KindPermissionDenied},
+		{"too many requests", &googleapi.Error{Code: 429}, KindRateLimited},
+		{"403 rate limit reason", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "userRateLimitExceeded"}}}, KindRateLimited},
+		{"server error", &googleapi.Error{Code: 503}, KindTransient},
+		{"deadline", fmt.Errorf("list: %w", context.DeadlineExceeded), KindTransient},
+		{"bad request", &googleapi.Error{Code: 400}, KindUnknown},
+		{"plain error", errors.New("boom"), KindUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceReturnsWorkspaceError(t *testing.T) {
+	ws := newTestService(t, testDrive, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": 404, "message": "File not found"}}`))
+	})
+
+	_, err := ws.ListDocComments(context.Background(), "doc-1")
+	var wsErr *WorkspaceError
+	if !errors.As(err, &wsErr) {
+		t.Fatalf("expected *WorkspaceError, got %T: %v", err, err)
+	}
+	if wsErr.Kind != KindNotFound {
+		t.Errorf("Kind = %v, want %v", wsErr.Kind, KindNotFound)
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+		t.Errorf("original googleapi error not reachable: %v", err)
+	}
+}
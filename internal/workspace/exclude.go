@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/exclude.go
+Description: Registry exclusion rules. Drive files in excluded folders or with
+excluded mime types are dropped from the registry and refused for deletion.
+*/
+package workspace
+
+import (
+	"errors"
+	"strings"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// ErrExcluded is returned when an operation targets a file hidden by the exclusion rules.
+var ErrExcluded = errors.New("file is excluded from the registry")
+
+// ExclusionRules lists Drive folders and mime types that axis must never surface.
+type ExclusionRules struct {
+	Folders   []string
+	MimeTypes []string
+}
+
+// ParseExclusionList splits a comma-separated setting into trimmed, non-empty entries.
+func ParseExclusionList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// Empty reports whether the rules exclude nothing.
+func (r ExclusionRules) Empty() bool {
+	return len(r.Folders) == 0 && len(r.MimeTypes) == 0
+}
+
+// excludes reports whether f matches any rule. A file is excluded when any of
+// its parents is an excluded folder.
+func (r ExclusionRules) excludes(f *drive.File) bool {
+	for _, mt := range r.MimeTypes {
+		if f.MimeType == mt {
+			return true
+		}
+	}
+	for _, parent := range f.Parents {
+		for _, folder := range r.Folders {
+			if parent == folder {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetExclusions installs the rules applied by ListRegistryItems and Drive deletes.
+func (s *Service) SetExclusions(rules ExclusionRules) {
+	s.exclusions = rules
+}
+
+// checkExcluded returns ErrExcluded when fileID matches the exclusion rules.
+func (s *Service) checkExcluded(fileID string) error {
+	if s.exclusions.Empty() {
+		return nil
+	}
+	f, err := s.driveService.Files.Get(fileID).Fields("id,mimeType,parents").SupportsAllDrives(true).Do()
+	if err != nil {
+		return apiErrorf(err, "unable to inspect file %s", fileID)
+	}
+	if s.exclusions.excludes(f) {
+		return ErrExcluded
+	}
+	return nil
+}
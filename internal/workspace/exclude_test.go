@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/exclude_test.go
+Description: Unit tests for registry exclusion rules.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	drive "google.golang.org/api/drive/v3"
+	keep "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
+)
+
+func TestListRegistryItemsExclusions(t *testing.T) {
+	var deletes int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		case r.URL.Path == "/files" && strings.Contains(r.URL.Query().Get("q"), "document"):
+			w.Write([]byte(`{"files": [
+				{"id": "doc-keep", "name": "Mine", "mimeType": "application/vnd.google-apps.document", "parents": ["root"]},
+				{"id": "doc-shared", "name": "Clutter", "mimeType": "application/vnd.google-apps.document", "parents": ["other", "shared-folder"]}
+			]}`))
+		case r.URL.Path == "/files":
+			w.Write([]byte(`{"files": [{"id": "sheet-1", "name": "Sheet", "mimeType": "application/vnd.google-apps.spreadsheet", "parents": ["root"]}]}`))
+		case r.URL.Path == "/files/doc-shared" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"id": "doc-shared", "mimeType": "application/vnd.google-apps.document", "parents": ["shared-folder"]}`))
+		case r.Method == http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+	ws.SetExclusions(ExclusionRules{
+		Folders:   []string{"shared-folder"},
+		MimeTypes: []string{"application/vnd.google-apps.spreadsheet"},
+	})
+
+	items, err := ws.ListRegistryItems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "doc-keep" {
+		t.Fatalf("expected only doc-keep to remain, got %+v", items)
+	}
+
+	if err := ws.DeleteDoc("doc-shared"); !errors.Is(err, ErrExcluded) {
+		t.Errorf("expected ErrExcluded deleting an excluded doc, got %v", err)
+	}
+	if deletes != 0 {
+		t.Errorf("expected no upstream delete for an excluded file, got %d", deletes)
+	}
+}
+
+func TestParseExclusionList(t *testing.T) {
+	got := ParseExclusionList(" a, ,b ,")
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := ParseExclusionList(""); got != nil {
+		t.Errorf("expected nil for an empty setting, got %v", got)
+	}
+}
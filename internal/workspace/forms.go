@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/forms.go
+Description: Google Forms support. Reads form structure through the Forms API,
+deletes forms through Drive, and renders a form's questions as plain text.
+*/
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	forms "google.golang.org/api/forms/v1"
+)
+
+const formMimeType = "application/vnd.google-apps.form"
+
+// ErrFormsUnavailable is returned by form reads when forms support is disabled
+// or the delegation grant lacks FormsScope.
+var ErrFormsUnavailable = errors.New("google forms service is not configured")
+
+// FormsEnabled reports whether form structure can be read.
+func (s *Service) FormsEnabled() bool {
+	return s.formsService != nil
+}
+
+// GetForm retrieves a form's structure
+func (s *Service) GetForm(formId string) (*forms.Form, error) {
+	if s.formsService == nil {
+		return nil, ErrFormsUnavailable
+	}
+	form, err := s.formsService.Forms.Get(formId).Do()
+	if err != nil {
+		return nil, apiErrorf(err, "unable to retrieve form %s", formId)
+	}
+	return form, nil
+}
+
+// DeleteForm removes a form via the Drive API
+func (s *Service) DeleteForm(formId string) error {
+	if err := s.checkExcluded(formId); err != nil {
+		return err
+	}
+	err := s.deleteDriveFile(formId)
+	if err != nil {
+		return apiErrorf(err, "unable to delete form %s", formId)
+	}
+	return nil
+}
+
+// ExtractFormContent renders a form's description and questions as text, one
+// numbered question per line followed by its choices.
+func ExtractFormContent(form *forms.Form) string {
+	if form == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	if form.Info != nil && strings.TrimSpace(form.Info.Description) != "" {
+		b.WriteString(strings.TrimSpace(form.Info.Description))
+		b.WriteString("\n\n")
+	}
+
+	n := 0
+	for _, item := range form.Items {
+		if item == nil {
+			continue
+		}
+		switch {
+		case item.QuestionItem != nil:
+			n++
+			writeFormQuestion(&b, n, item.Title, item.QuestionItem.Question)
+		case item.QuestionGroupItem != nil:
+			n++
+			fmt.Fprintf(&b, "%d. %s\n", n, item.Title)
+			group := item.QuestionGroupItem
+			for _, q := range group.Questions {
+				if q != nil && q.RowQuestion != nil {
+					fmt.Fprintf(&b, "   - %s\n", q.RowQuestion.Title)
+				}
+			}
+			if group.Grid != nil {
+				writeFormChoices(&b, group.Grid.Columns)
+			}
+		case item.PageBreakItem != nil || item.TextItem != nil:
+			if item.Title != "" {
+				fmt.Fprintf(&b, "\n## %s\n", item.Title)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func writeFormQuestion(b *strings.Builder, n int, title string, q *forms.Question) {
+	required := ""
+	if q != nil && q.Required {
+		required = " *"
+	}
+	fmt.Fprintf(b, "%d. %s%s\n", n, title, required)
+	if q != nil {
+		writeFormChoices(b, q.ChoiceQuestion)
+	}
+}
+
+func writeFormChoices(b *strings.Builder, choice *forms.ChoiceQuestion) {
+	if choice == nil {
+		return
+	}
+	for _, opt := range choice.Options {
+		if opt == nil {
+			continue
+		}
+		label := opt.Value
+		if opt.IsOther {
+			label = "Other"
+		}
+		fmt.Fprintf(b, "   [ ] %s\n", label)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/forms_test.go
+Description: Unit tests for Google Forms registry listing and content rendering.
+*/
+package workspace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	drive "google.golang.org/api/drive/v3"
+	forms "google.golang.org/api/forms/v1"
+	keep "google.golang.org/api/keep/v1"
+	"google.golang.org/api/option"
+)
+
+func TestListRegistryItemsIncludesForms(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		case strings.Contains(r.URL.Query().Get("q"), formMimeType):
+			w.Write([]byte(`{"files": [{"id": "form-1", "name": "Survey", "mimeType": "` + formMimeType + `"}]}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+	items, err := ws.ListRegistryItems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", items)
+	}
+	if items[0].ID != "form-1" || items[0].Type != "form" || items[0].Title != "Survey" {
+		t.Errorf("expected the form registry item, got %+v", items[0])
+	}
+}
+
+func TestGetFormRendersQuestions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/forms/form-1" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"formId": "form-1",
+			"info": {"title": "Survey", "description": "Tell us"},
+			"items": [
+				{"title": "Name", "questionItem": {"question": {"required": true, "textQuestion": {}}}},
+				{"title": "Colour", "questionItem": {"question": {"choiceQuestion": {"type": "RADIO", "options": [{"value": "Red"}, {"isOther": true}]}}}}
+			]
+		}`))
+	}))
+	defer ts.Close()
+
+	formsSvc, err := forms.NewService(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := NewService(nil, nil, nil, nil, nil, nil, nil, nil, formsSvc)
+
+	form, err := ws.GetForm("form-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Tell us\n\n1. Name *\n2. Colour\n   [ ] Red\n   [ ] Other"
+	if got := ExtractFormContent(form); got != want {
+		t.Errorf("expected content:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -33,6 +33,9 @@ type Note struct {
 
 var errKeepUnavailable = errors.New("google keep service is not configured")
 
+// NoBodyContent is the text reported for notes whose body has no text or list items.
+const NoBodyContent = "No body content."
+
 // ListNotesOptions allows callers to control pagination and filtering.
 type ListNotesOptions struct {
 	Filter    string
@@ -55,7 +58,7 @@ func (s *Service) ListNoteSummaries(ctx context.Context, opts ListNotesOptions)
 
 	summaries := make([]Note, 0, len(resp.Notes))
 	for _, note := range resp.Notes {
-		summaries = append(summaries, summarizeNote(note))
+		summaries = append(summaries, SummarizeNote(note))
 	}
 
 	return summaries, resp.NextPageToken, nil
@@ -108,6 +111,48 @@ func (s *Service) ListAllKeepNotes(ctx context.Context, opts ListNotesOptions) (
 	return all, nil
 }
 
+// SearchNotes returns the notes whose title or content contains every
+// whitespace-separated term of query, ignoring case. The Keep API can only
+// filter on times and trash state, so matching happens here over a full listing.
+func (s *Service) SearchNotes(ctx context.Context, query string) ([]*keepapi.Note, error) {
+	notes, err := s.ListAllKeepNotes(ctx, ListNotesOptions{})
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query))
+	var matches []*keepapi.Note
+	for _, note := range notes {
+		if noteMatches(note, terms) {
+			matches = append(matches, note)
+		}
+	}
+	return matches, nil
+}
+
+func noteMatches(note *keepapi.Note, terms []string) bool {
+	if note == nil {
+		return false
+	}
+	var b strings.Builder
+	b.WriteString(note.Title)
+	if note.Body != nil {
+		if note.Body.Text != nil {
+			b.WriteString("\n")
+			b.WriteString(note.Body.Text.Text)
+		}
+		if note.Body.List != nil {
+			appendListText(&b, note.Body.List.ListItems)
+		}
+	}
+	text := strings.ToLower(b.String())
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetNote retrieves a single keep note.
 func (s *Service) GetNote(ctx context.Context, noteID string) (*keepapi.Note, error) {
 	svc, err := s.ensureKeepService()
@@ -117,7 +162,7 @@ func (s *Service) GetNote(ctx context.Context, noteID string) (*keepapi.Note, er
 	name := ensureNoteName(noteID)
 	note, err := svc.Notes.Get(name).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get note %s: %w", name, err)
+		return nil, apiErrorf(err, "unable to get note %s", name)
 	}
 	return note, nil
 }
@@ -133,7 +178,7 @@ func (s *Service) CreateNote(ctx context.Context, note *keepapi.Note) (*keepapi.
 	}
 	created, err := svc.Notes.Create(note).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to create note: %w", err)
+		return nil, apiErrorf(err, "unable to create note")
 	}
 	return created, nil
 }
@@ -175,11 +220,37 @@ func (s *Service) DeleteNote(ctx context.Context, noteID string) error {
 	name := ensureNoteName(noteID)
 	_, err = svc.Notes.Delete(name).Context(ctx).Do()
 	if err != nil {
-		return fmt.Errorf("unable to delete note %s: %w", name, err)
+		return apiErrorf(err, "unable to delete note %s", name)
 	}
 	return nil
 }
 
+// ErrChecklistNote is returned when a text edit targets a checklist note.
+var ErrChecklistNote = errors.New("checklist notes cannot be edited as text")
+
+// UpdateNoteBody replaces the text body of a keep note. The Keep API has no
+// update call, so the note is recreated with the same title and the new body and
+// the original is deleted. The returned note carries the new name; sharing on the
+// original note is not carried over.
+func (s *Service) UpdateNoteBody(ctx context.Context, noteID, body string) (*keepapi.Note, error) {
+	existing, err := s.GetNote(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Body != nil && existing.Body.List != nil {
+		return nil, ErrChecklistNote
+	}
+
+	updated, err := s.CreateTextNote(ctx, existing.Title, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DeleteNote(ctx, existing.Name); err != nil {
+		return updated, fmt.Errorf("note rewritten as %s but the original was kept: %w", updated.Name, err)
+	}
+	return updated, nil
+}
+
 // AddNoteWriters grants writer access to the specified note for the provided emails.
 func (s *Service) AddNoteWriters(ctx context.Context, noteID string, writerEmails []string) ([]*keepapi.Permission, error) {
 	if len(writerEmails) == 0 {
@@ -211,7 +282,7 @@ func (s *Service) AddNoteWriters(ctx context.Context, noteID string, writerEmail
 
 	resp, err := svc.Notes.Permissions.BatchCreate(parent, &keepapi.BatchCreatePermissionsRequest{Requests: requests}).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to add writer permissions for %s: %w", parent, err)
+		return nil, apiErrorf(err, "unable to add writer permissions for %s", parent)
 	}
 	return resp.Permissions, nil
 }
@@ -240,7 +311,7 @@ func (s *Service) RemoveNotePermissions(ctx context.Context, noteID string, perm
 
 	_, err = svc.Notes.Permissions.BatchDelete(parent, &keepapi.BatchDeletePermissionsRequest{Names: names}).Context(ctx).Do()
 	if err != nil {
-		return fmt.Errorf("unable to remove permissions for %s: %w", parent, err)
+		return apiErrorf(err, "unable to remove permissions for %s", parent)
 	}
 	return nil
 }
@@ -253,7 +324,7 @@ func (s *Service) GetAttachmentMetadata(ctx context.Context, attachmentName stri
 	}
 	attachment, err := svc.Media.Download(attachmentName).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to fetch attachment %s metadata: %w", attachmentName, err)
+		return nil, apiErrorf(err, "unable to fetch attachment %s metadata", attachmentName)
 	}
 	return attachment, nil
 }
@@ -270,7 +341,7 @@ func (s *Service) DownloadAttachmentMedia(ctx context.Context, attachmentName, m
 	}
 	resp, err := call.Download()
 	if err != nil {
-		return nil, fmt.Errorf("unable to download attachment %s: %w", attachmentName, err)
+		return nil, apiErrorf(err, "unable to download attachment %s", attachmentName)
 	}
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
@@ -304,28 +375,48 @@ func (s *Service) listNotes(ctx context.Context, opts ListNotesOptions) (*keepap
 	}
 	resp, err := call.Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to list notes: %w", err)
+		return nil, apiErrorf(err, "unable to list notes")
 	}
 	return resp, nil
 }
 
-func summarizeNote(note *keepapi.Note) Note {
+// SummarizeNote reduces a Keep note to its ID, sanitized title and snippet.
+func SummarizeNote(note *keepapi.Note) Note {
 	if note == nil {
-		return Note{Title: "Untitled", Snippet: "..."}
-	}
-
-	title := strings.TrimSpace(note.Title)
-	if title == "" {
-		title = "Untitled"
+		return Note{Title: SanitizeNoteTitle(""), Snippet: "..."}
 	}
 
 	return Note{
 		ID:      note.Name,
-		Title:   title,
+		Title:   SanitizeNoteTitle(note.Title),
 		Snippet: noteSnippet(note.Body),
 	}
 }
 
+// NoteCollaborators counts the people or groups a note is shared with: every
+// live permission other than the owner's.
+func NoteCollaborators(note *keepapi.Note) int {
+	if note == nil {
+		return 0
+	}
+	n := 0
+	for _, perm := range note.Permissions {
+		if perm != nil && !perm.Deleted && perm.Role != "OWNER" {
+			n++
+		}
+	}
+	return n
+}
+
+// SanitizeNoteTitle trims a note title, substituting "Untitled" when it is empty.
+func SanitizeNoteTitle(raw string) string {
+	t := strings.TrimSpace(raw)
+	if t == "" {
+		return "Untitled"
+	}
+	return t
+}
+
 func noteSnippet(section *keepapi.Section) string {
 	if section == nil {
 		return "..."
@@ -353,9 +444,10 @@ func truncateSnippet(src string) string {
 }
 
 // ExtractFullContent flattens a Keep section into markdown-friendly text for downstream agents.
+// Missing or empty bodies yield NoBodyContent.
 func ExtractFullContent(section *keepapi.Section) string {
 	if section == nil {
-		return ""
+		return NoBodyContent
 	}
 
 	if section.Text != nil {
@@ -368,10 +460,12 @@ func ExtractFullContent(section *keepapi.Section) string {
 	if section.List != nil && len(section.List.ListItems) > 0 {
 		var b strings.Builder
 		appendListContent(&b, section.List.ListItems, 0)
-		return strings.TrimSpace(b.String())
+		if text := strings.TrimSpace(b.String()); text != "" {
+			return text
+		}
 	}
 
-	return ""
+	return NoBodyContent
 }
 
 func appendListContent(b *strings.Builder, items []*keepapi.ListItem, depth int) {
@@ -407,6 +501,20 @@ func appendListContent(b *strings.Builder, items []*keepapi.ListItem, depth int)
 	}
 }
 
+// appendListText writes the text of each list item, without the checkbox
+// markers and placeholders ExtractFullContent adds, so searches match only what
+// the user wrote.
+func appendListText(b *strings.Builder, items []*keepapi.ListItem) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(listItemText(item))
+		appendListText(b, item.ChildListItems)
+	}
+}
+
 func listItemText(item *keepapi.ListItem) string {
 	if item == nil || item.Text == nil {
 		return ""
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/keep_test.go
+Description: Unit tests for Keep note helpers.
+*/
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	keep "google.golang.org/api/keep/v1"
+)
+
+func TestUpdateNoteBody(t *testing.T) {
+	var created keep.Note
+	var deleted string
+	svc := newTestService(t, testKeep, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/notes/old":
+			w.Write([]byte(`{"name": "notes/old", "title": "Groceries", "body": {"text": {"text": "milk"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/notes":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("decode create payload: %v", err)
+			}
+			w.Write([]byte(`{"name": "notes/new", "title": "Groceries", "body": {"text": {"text": "milk, eggs"}}}`))
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	note, err := svc.UpdateNoteBody(context.Background(), "old", "milk, eggs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.Name != "notes/new" {
+		t.Errorf("expected the recreated note name, got %s", note.Name)
+	}
+	if created.Title != "Groceries" {
+		t.Errorf("expected the title to be preserved, got %q", created.Title)
+	}
+	if created.Body == nil || created.Body.Text == nil || created.Body.Text.Text != "milk, eggs" {
+		t.Errorf("expected the new text in the body section, got %+v", created.Body)
+	}
+	if created.Body != nil && created.Body.List != nil {
+		t.Error("expected no list section in the payload")
+	}
+	if deleted != "/v1/notes/old" {
+		t.Errorf("expected the original note to be deleted, got %q", deleted)
+	}
+}
+
+func TestUpdateNoteBodyRejectsChecklist(t *testing.T) {
+	svc := newTestService(t, testKeep, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected write %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "notes/list", "title": "Todo", "body": {"list": {"listItems": [{"text": {"text": "a"}}]}}}`))
+	})
+
+	if _, err := svc.UpdateNoteBody(context.Background(), "notes/list", "text"); !errors.Is(err, ErrChecklistNote) {
+		t.Fatalf("expected ErrChecklistNote, got %v", err)
+	}
+}
+
+func TestNotesWithoutTitleOrBody(t *testing.T) {
+	svc := newTestService(t, testKeep, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"notes": [
+			{"name": "notes/untitled", "body": {"text": {"text": "hello"}}},
+			{"name": "notes/empty", "title": "Empty", "body": {}},
+			{"name": "notes/bare", "title": "  "}
+		]}`))
+	})
+
+	summaries, err := svc.ListNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Note{
+		{ID: "notes/untitled", Title: "Untitled", Snippet: "hello"},
+		{ID: "notes/empty", Title: "Empty", Snippet: "..."},
+		{ID: "notes/bare", Title: "Untitled", Snippet: "..."},
+	}
+	if len(summaries) != len(want) {
+		t.Fatalf("expected %d summaries, got %+v", len(want), summaries)
+	}
+	for i := range want {
+		if summaries[i] != want[i] {
+			t.Errorf("summary %d: expected %+v, got %+v", i, want[i], summaries[i])
+		}
+	}
+
+	for _, section := range []*keep.Section{nil, {}, {Text: &keep.TextContent{}}, {List: &keep.ListContent{}}} {
+		if got := ExtractFullContent(section); got != NoBodyContent {
+			t.Errorf("expected %q for %+v, got %q", NoBodyContent, section, got)
+		}
+	}
+}
+
+func TestNoteMatchesIgnoresRenderedPlaceholders(t *testing.T) {
+	empty := &keep.Note{Title: "Empty", Body: &keep.Section{}}
+	list := &keep.Note{Title: "Groceries", Body: &keep.Section{List: &keep.ListContent{ListItems: []*keep.ListItem{
+		{Text: &keep.TextContent{Text: "Milk"}, Checked: true, ChildListItems: []*keep.ListItem{
+			{Text: &keep.TextContent{Text: "Oat"}},
+		}},
+		{},
+	}}}}
+
+	for _, tc := range []struct {
+		note  *keep.Note
+		terms []string
+		want  bool
+	}{
+		{empty, []string{"body content"}, false},
+		{list, []string{"milk", "oat"}, true},
+		{list, []string{"[x]"}, false},
+		{list, []string{"empty"}, false},
+	} {
+		if got := noteMatches(tc.note, tc.terms); got != tc.want {
+			t.Errorf("noteMatches(%q, %q) = %v, want %v", tc.note.Title, tc.terms, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/retry.go
+Description: Shared retry budget for upstream calls. A budget attached to a
+context caps the retries of every call made under it, so an outage across
+several sources cannot multiply into a retry storm.
+*/
+package workspace
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// retryDelay is the pause before each retry.
+var retryDelay = 200 * time.Millisecond
+
+// RetryBudget is a pool of retries shared by every call made under one context.
+type RetryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// NewRetryBudget returns a budget allowing retries retries in total.
+func NewRetryBudget(retries int) *RetryBudget {
+	return &RetryBudget{remaining: retries}
+}
+
+// take spends one retry, reporting false once the budget is exhausted.
+func (b *RetryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// Remaining reports how many retries are left.
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining
+}
+
+type retryBudgetKey struct{}
+
+// WithRetryBudget attaches budget to ctx. Calls made under a context without a
+// budget are not retried.
+func WithRetryBudget(ctx context.Context, budget *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// retryDo runs do, retrying transient and rate-limited failures while the budget
+// in ctx lasts. Once it is exhausted failures are returned immediately.
+func retryDo[T any](ctx context.Context, do func(...googleapi.CallOption) (T, error)) (T, error) {
+	budget, _ := ctx.Value(retryBudgetKey{}).(*RetryBudget)
+	for {
+		res, err := do()
+		if err == nil || budget == nil {
+			return res, err
+		}
+		if kind := classifyError(err); kind != KindTransient && kind != KindRateLimited {
+			return res, err
+		}
+		if !budget.take() {
+			return res, err
+		}
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return res, err
+		}
+	}
+}
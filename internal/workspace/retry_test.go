@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/retry_test.go
+Description: Unit tests for the shared retry budget and partial registry listings.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRetryBudgetCapsAttemptsAcrossSources(t *testing.T) {
+	saved := retryDelay
+	retryDelay = 0
+	t.Cleanup(func() { retryDelay = saved })
+
+	var attempts atomic.Int32
+	ws := newTestService(t, testKeep|testDrive, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, `{"error": {"code": 503, "message": "backend unavailable"}}`, http.StatusServiceUnavailable)
+	})
+
+	budget := NewRetryBudget(3)
+	_, err := ws.ListRegistryItems(WithRetryBudget(context.Background(), budget))
+	if err == nil {
+		t.Fatal("expected an error when every source fails")
+	}
+	var partial *PartialListError
+	if errors.As(err, &partial) {
+		t.Errorf("expected a plain error when nothing listed, got %v", err)
+	}
+	// Keep and Drive each make one first attempt; the budget adds at most 3 more.
+	if n := attempts.Load(); n != 2+3 {
+		t.Errorf("expected 5 upstream attempts, got %d", n)
+	}
+	if budget.Remaining() != 0 {
+		t.Errorf("expected the budget to be spent, %d left", budget.Remaining())
+	}
+}
+
+func TestListRegistryItemsReturnsPartialResults(t *testing.T) {
+	ws := newTestService(t, testKeep|testDrive, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/notes" {
+			http.Error(w, `{"error": {"code": 403, "message": "denied"}}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"files": [{"id": "file-1", "name": "Plan"}]}`))
+	})
+
+	items, err := ws.ListRegistryItems(context.Background())
+	var partial *PartialListError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialListError, got %v", err)
+	}
+	if len(partial.Types) != 1 || partial.Types[0] != "keep" {
+		t.Errorf("expected only keep to be missing, got %v", partial.Types)
+	}
+	if KindOf(err) != KindPermissionDenied {
+		t.Errorf("expected the underlying kind to survive, got %v", KindOf(err))
+	}
+	if len(items) != 1 || items[0].ID != "file-1" {
+		t.Errorf("expected the Drive items to be returned, got %+v", items)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/snippets.go
+Description: Content-derived registry snippets. The Drive list carries no content,
+so docs and sheets are fetched individually to build a short preview.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	docs "google.golang.org/api/docs/v1"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// FetchSnippet builds a content preview for a doc or sheet registry item. Other
+// types have nothing to fetch and keep their existing snippet.
+func (s *Service) FetchSnippet(ctx context.Context, item RegistryItem) (string, error) {
+	switch item.Type {
+	case "doc":
+		doc, err := retryDo(ctx, s.docsService.Documents.Get(item.ID).Context(ctx).Do)
+		if err != nil {
+			return "", apiErrorf(err, "unable to retrieve doc %s", item.ID)
+		}
+		return DocSnippet(doc), nil
+	case "sheet":
+		sheet, err := retryDo(ctx, s.sheetsService.Spreadsheets.Get(item.ID).Fields("sheets.properties").Context(ctx).Do)
+		if err != nil {
+			return "", apiErrorf(err, "unable to retrieve sheet %s", item.ID)
+		}
+		return SheetSnippet(sheet), nil
+	default:
+		return item.Snippet, nil
+	}
+}
+
+// DocSnippet returns the first non-blank line of a doc, or "Empty document".
+func DocSnippet(doc *docs.Document) string {
+	if doc != nil && doc.Body != nil {
+		for _, line := range strings.Split(ExtractDocContent(doc.Body.Content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return truncateSnippet(line)
+			}
+		}
+	}
+	return "Empty document"
+}
+
+// SheetSnippet summarizes a spreadsheet's tabs and total grid rows, e.g.
+// "3 tabs, 120 rows".
+func SheetSnippet(sheet *sheets.Spreadsheet) string {
+	if sheet == nil {
+		return "0 tabs"
+	}
+	var rows int64
+	for _, tab := range sheet.Sheets {
+		if tab != nil && tab.Properties != nil && tab.Properties.GridProperties != nil {
+			rows += tab.Properties.GridProperties.RowCount
+		}
+	}
+	return fmt.Sprintf("%s, %s", plural(len(sheet.Sheets), "tab"), plural(int(rows), "row"))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
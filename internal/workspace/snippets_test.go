@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Justin Andrew Wood. All rights reserved.
+// This software is licensed under the AGPL-3.0.
+// Commercial licensing is available at echosh-labs.com.
+/*
+File: internal/workspace/snippets_test.go
+Description: Unit tests for content-derived doc and sheet snippets.
+*/
+package workspace
+
+import (
+	"testing"
+
+	docs "google.golang.org/api/docs/v1"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestDocSnippetUsesFirstLine(t *testing.T) {
+	doc := &docs.Document{Body: &docs.Body{Content: []*docs.StructuralElement{
+		{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: "\n"}}}}},
+		{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: "  Q3 planning notes\n"}}}}},
+		{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: "Second line\n"}}}}},
+	}}}
+	if got := DocSnippet(doc); got != "Q3 planning notes" {
+		t.Errorf("expected the first non-blank line, got %q", got)
+	}
+	if got := DocSnippet(&docs.Document{}); got != "Empty document" {
+		t.Errorf("expected the empty placeholder, got %q", got)
+	}
+}
+
+func TestSheetSnippetSummarizesTabs(t *testing.T) {
+	tab := func(rows int64) *sheets.Sheet {
+		return &sheets.Sheet{Properties: &sheets.SheetProperties{GridProperties: &sheets.GridProperties{RowCount: rows}}}
+	}
+	sheet := &sheets.Spreadsheet{Sheets: []*sheets.Sheet{tab(100), tab(15), tab(5)}}
+	if got := SheetSnippet(sheet); got != "3 tabs, 120 rows" {
+		t.Errorf("expected %q, got %q", "3 tabs, 120 rows", got)
+	}
+	if got := SheetSnippet(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{tab(1)}}); got != "1 tab, 1 row" {
+		t.Errorf("expected singular nouns, got %q", got)
+	}
+}
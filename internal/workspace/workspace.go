@@ -9,15 +9,22 @@ initialization logic for interfacing with Google Admin and Keep APIs.
 package workspace
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
+	"golang.org/x/oauth2"
 	admin "google.golang.org/api/admin/directory/v1"
 	chat "google.golang.org/api/chat/v1"
 	docs "google.golang.org/api/docs/v1"
 	drive "google.golang.org/api/drive/v3"
+	forms "google.golang.org/api/forms/v1"
 	gmail "google.golang.org/api/gmail/v1"
 	keep "google.golang.org/api/keep/v1"
 	sheets "google.golang.org/api/sheets/v4"
@@ -34,6 +41,20 @@ type Service struct {
 	gmailService  *gmail.Service
 	chatUserSvc   *chat.Service
 	chatBotSvc    *chat.Service
+	formsService  *forms.Service
+
+	// tokenSource and scopes are the delegated credentials the clients use, so
+	// CheckScopes can test the token exchange on its own. Nil when the clients
+	// were built elsewhere.
+	tokenSource oauth2.TokenSource
+	scopes      []string
+
+	exclusions    ExclusionRules
+	sharedDriveID string
+	ownedOnly     bool
+
+	// logger receives diagnostics; nil means slog's default logger.
+	logger atomic.Pointer[slog.Logger]
 }
 
 // User represents a simplified user structure
@@ -45,13 +66,24 @@ type User struct {
 
 // RegistryItem defines a unified structure for frontend display.
 type RegistryItem struct {
-	ID      string `json:"id"`
-	Type    string `json:"type"`
-	Title   string `json:"title"`
-	Snippet string `json:"snippet"`
-	Status  string `json:"status,omitempty"`
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	Snippet      string `json:"snippet"`
+	Status       string `json:"status,omitempty"`
+	Annotation   string `json:"annotation,omitempty"`
+	CreatedTime  string `json:"createdTime,omitempty"`
+	ModifiedTime string `json:"modifiedTime,omitempty"`
+	// Shared is set on Keep notes with collaborators; Collaborators counts them.
+	Shared        bool `json:"shared,omitempty"`
+	Collaborators int  `json:"collaborators,omitempty"`
+	// Protected items are refused by every delete handler.
+	Protected bool `json:"protected,omitempty"`
 }
 
+// driveListFields selects the file metadata the registry needs from Drive.
+const driveListFields = "files(id,name,mimeType,parents,ownedByMe,createdTime,modifiedTime)"
+
 // NewService creates a new workspace service wrapper
 func NewService(
 	adminSvc *admin.Service,
@@ -62,6 +94,7 @@ func NewService(
 	gmailSvc *gmail.Service,
 	chatUserSvc *chat.Service,
 	chatBotSvc *chat.Service,
+	formsSvc *forms.Service,
 ) *Service {
 	return &Service{
 		adminService:  adminSvc,
@@ -72,14 +105,28 @@ func NewService(
 		gmailService:  gmailSvc,
 		chatUserSvc:   chatUserSvc,
 		chatBotSvc:    chatBotSvc,
+		formsService:  formsSvc,
+	}
+}
+
+// SetLogger routes the service's diagnostics, such as dropped duplicate
+// registry items, to logger.
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger.Store(logger)
+}
+
+func (s *Service) log() *slog.Logger {
+	if logger := s.logger.Load(); logger != nil {
+		return logger
 	}
+	return slog.Default()
 }
 
 // GetUser retrieves a user by email
 func (s *Service) GetUser(email string) (*User, error) {
 	u, err := s.adminService.Users.Get(email).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve user %s: %w", email, err)
+		return nil, apiErrorf(err, "unable to retrieve user %s", email)
 	}
 
 	return &User{
@@ -89,59 +136,162 @@ func (s *Service) GetUser(email string) (*User, error) {
 	}, nil
 }
 
-// ListRegistryItems provides a consolidated list of Keep, Docs, and Sheets.
-func (s *Service) ListRegistryItems() ([]RegistryItem, error) {
+// ListRegistryItems provides a consolidated list of Keep notes, Drive files, and Gmail threads.
+// A failing source does not hide the others: when at least one source succeeds the
+// items gathered are returned with a *PartialListError naming the missing types.
+func (s *Service) ListRegistryItems(ctx context.Context) ([]RegistryItem, error) {
+	type source struct {
+		types []string
+		list  func(context.Context) ([]RegistryItem, error)
+	}
+	sources := []source{
+		{[]string{"keep"}, s.ListKeepRegistryItems},
+		{[]string{"doc", "sheet", "form"}, s.ListDriveRegistryItems},
+	}
+	if s.gmailService != nil {
+		sources = append(sources, source{[]string{"gmail"}, s.ListGmailRegistryItems})
+	}
+
 	var items []RegistryItem
+	var partial *PartialListError
+	failed := 0
+	for _, source := range sources {
+		listed, err := source.list(ctx)
+		if err != nil {
+			if partial == nil {
+				partial = &PartialListError{Err: err}
+			}
+			partial.Types = append(partial.Types, source.types...)
+			failed++
+			continue
+		}
+		items = append(items, listed...)
+	}
+	switch {
+	case failed == len(sources):
+		return nil, partial.Err
+	case partial != nil:
+		return dedupeRegistryItems(s.log(), items), partial
+	}
+	return dedupeRegistryItems(s.log(), items), nil
+}
 
-	// 1. Fetch Keep Notes
-	notes, err := s.keepService.Notes.List().Do()
+// dedupeRegistryItems drops repeated IDs, keeping the first occurrence. Drive can
+// list a file once per parent folder, which would otherwise show it twice. Each
+// dropped item is logged to logger.
+func dedupeRegistryItems(logger *slog.Logger, items []RegistryItem) []RegistryItem {
+	seen := make(map[string]bool, len(items))
+	res := items[:0]
+	for _, item := range items {
+		if seen[item.ID] {
+			logger.Warn("dropping duplicate registry item", "id", item.ID, "type", item.Type, "title", item.Title)
+			continue
+		}
+		seen[item.ID] = true
+		res = append(res, item)
+	}
+	return res
+}
+
+// ListKeepRegistryItems lists the registry entries for untrashed Keep notes.
+func (s *Service) ListKeepRegistryItems(ctx context.Context) ([]RegistryItem, error) {
+	var items []RegistryItem
+
+	notes, err := retryDo(ctx, s.keepService.Notes.List().Context(ctx).Do)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list keep notes: %w", err)
+		return nil, apiErrorf(err, "failed to list keep notes")
 	}
 	for _, note := range notes.Notes {
-		if !note.Trashed {
+		if note != nil && !note.Trashed {
+			collaborators := NoteCollaborators(note)
 			items = append(items, RegistryItem{
-				ID:      note.Name,
-				Type:    "keep",
-				Title:   note.Title,
-				Snippet: "Google Keep Note",
+				ID:            note.Name,
+				Type:          "keep",
+				Title:         SanitizeNoteTitle(note.Title),
+				Snippet:       "Google Keep Note",
+				CreatedTime:   normalizeTimestamp(note.CreateTime),
+				ModifiedTime:  normalizeTimestamp(note.UpdateTime),
+				Shared:        collaborators > 0,
+				Collaborators: collaborators,
 			})
 		}
 	}
+	return items, nil
+}
+
+// ListDriveRegistryItems lists the registry entries for Docs, Sheets, and Forms.
+func (s *Service) ListDriveRegistryItems(ctx context.Context) ([]RegistryItem, error) {
+	var items []RegistryItem
 
-	// 2. Fetch Google Docs
-	docsList, err := s.driveService.Files.List().Q("mimeType='application/vnd.google-apps.document' and trashed=false").PageSize(50).Do()
+	// Google Docs
+	docsList, err := retryDo(ctx, s.listDriveFiles("mimeType='application/vnd.google-apps.document' and trashed=false").Fields(driveListFields).PageSize(50).Context(ctx).Do)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list docs: %w", err)
+		return nil, apiErrorf(err, "failed to list docs")
 	}
 	for _, file := range docsList.Files {
+		if s.hidesDriveFile(file) {
+			continue
+		}
 		items = append(items, RegistryItem{
-			ID:      file.Id,
-			Type:    "doc",
-			Title:   file.Name,
-			Snippet: "Google Doc",
+			ID:           file.Id,
+			Type:         "doc",
+			Title:        file.Name,
+			Snippet:      "Google Doc",
+			CreatedTime:  normalizeTimestamp(file.CreatedTime),
+			ModifiedTime: normalizeTimestamp(file.ModifiedTime),
 		})
 	}
 
-	// 3. Fetch Google Sheets
-	sheetsList, err := s.driveService.Files.List().Q("mimeType='application/vnd.google-apps.spreadsheet' and trashed=false").PageSize(50).Do()
+	// Google Sheets
+	sheetsList, err := retryDo(ctx, s.listDriveFiles("mimeType='application/vnd.google-apps.spreadsheet' and trashed=false").Fields(driveListFields).PageSize(50).Context(ctx).Do)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list sheets: %w", err)
+		return nil, apiErrorf(err, "failed to list sheets")
 	}
 	for _, file := range sheetsList.Files {
+		if s.hidesDriveFile(file) {
+			continue
+		}
 		items = append(items, RegistryItem{
-			ID:      file.Id,
-			Type:    "sheet",
-			Title:   file.Name,
-			Snippet: "Google Sheet",
+			ID:           file.Id,
+			Type:         "sheet",
+			Title:        file.Name,
+			Snippet:      "Google Sheet",
+			CreatedTime:  normalizeTimestamp(file.CreatedTime),
+			ModifiedTime: normalizeTimestamp(file.ModifiedTime),
 		})
 	}
 
-	// 4. Fetch Gmail Threads
+	// Google Forms
+	formsList, err := retryDo(ctx, s.listDriveFiles("mimeType='"+formMimeType+"' and trashed=false").Fields(driveListFields).PageSize(50).Context(ctx).Do)
+	if err != nil {
+		return nil, apiErrorf(err, "failed to list forms")
+	}
+	for _, file := range formsList.Files {
+		if s.hidesDriveFile(file) {
+			continue
+		}
+		items = append(items, RegistryItem{
+			ID:           file.Id,
+			Type:         "form",
+			Title:        file.Name,
+			Snippet:      "Google Form",
+			CreatedTime:  normalizeTimestamp(file.CreatedTime),
+			ModifiedTime: normalizeTimestamp(file.ModifiedTime),
+		})
+	}
+
+	return dedupeRegistryItems(s.log(), items), nil
+}
+
+// ListGmailRegistryItems lists the registry entries for inbox threads. It returns
+// nothing when Gmail is not configured.
+func (s *Service) ListGmailRegistryItems(ctx context.Context) ([]RegistryItem, error) {
+	var items []RegistryItem
+
 	if s.gmailService != nil {
-		threadsList, err := s.gmailService.Users.Threads.List("me").Q("in:inbox").MaxResults(50).Do()
+		threadsList, err := retryDo(ctx, s.gmailService.Users.Threads.List("me").Q("in:inbox").MaxResults(50).Context(ctx).Do)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list gmail threads: %w", err)
+			return nil, apiErrorf(err, "failed to list gmail threads")
 		}
 
 		var wg sync.WaitGroup
@@ -153,13 +303,14 @@ func (s *Service) ListRegistryItems() ([]RegistryItem, error) {
 				defer wg.Done()
 
 				// Fetch thread metadata for Subject
-				fullThread, err := s.gmailService.Users.Threads.Get("me", th.Id).Format("metadata").MetadataHeaders("Subject").Do()
+				fullThread, err := s.gmailService.Users.Threads.Get("me", th.Id).Format("metadata").MetadataHeaders("Subject").Context(ctx).Do()
 				if err != nil {
 					return
 				}
 
 				title := "No Subject"
 				status := ""
+				var created, modified string
 
 				if len(fullThread.Messages) > 0 {
 					msg := fullThread.Messages[0]
@@ -177,43 +328,214 @@ func (s *Service) ListRegistryItems() ([]RegistryItem, error) {
 						}
 					}
 					status = strings.Join(importantLabels, ", ")
+
+					created = millisTimestamp(msg.InternalDate)
+					modified = millisTimestamp(fullThread.Messages[len(fullThread.Messages)-1].InternalDate)
 				}
 
 				mu.Lock()
 				items = append(items, RegistryItem{
-					ID:      th.Id,
-					Type:    "gmail",
-					Title:   title,
-					Snippet: th.Snippet,
-					Status:  status,
+					ID:           th.Id,
+					Type:         "gmail",
+					Title:        title,
+					Snippet:      th.Snippet,
+					Status:       status,
+					CreatedTime:  created,
+					ModifiedTime: modified,
 				})
 				mu.Unlock()
 			}(thread)
 		}
 		wg.Wait()
 	}
-
 	return items, nil
 }
 
+// normalizeTimestamp re-renders an API timestamp as second-precision RFC3339 UTC.
+// Unparseable or empty values yield "" so the field is omitted.
+func normalizeTimestamp(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// millisTimestamp converts a Gmail internalDate (epoch milliseconds) to RFC3339 UTC.
+func millisTimestamp(ms int64) string {
+	if ms <= 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
 // GetSheet retrieves a Google Sheet and its values by ID
 func (s *Service) GetSheet(spreadsheetId string) (*sheets.Spreadsheet, error) {
 	sheet, err := s.sheetsService.Spreadsheets.Get(spreadsheetId).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve sheet %s: %w", spreadsheetId, err)
+		return nil, apiErrorf(err, "unable to retrieve sheet %s", spreadsheetId)
 	}
 	return sheet, nil
 }
 
-// GetSheetValues pulls the explicit tabular grid data from a range
-func (s *Service) GetSheetValues(spreadsheetId string, readRange string) (*sheets.ValueRange, error) {
-	resp, err := s.sheetsService.Spreadsheets.Values.Get(spreadsheetId, readRange).Do()
+// Value render options accepted by GetSheetValues. FORMULA returns the
+// underlying formulas instead of their computed values.
+const (
+	RenderFormattedValue   = "FORMATTED_VALUE"
+	RenderUnformattedValue = "UNFORMATTED_VALUE"
+	RenderFormula          = "FORMULA"
+)
+
+// ValidSheetRender reports whether render is a value render option GetSheetValues accepts.
+func ValidSheetRender(render string) bool {
+	switch render {
+	case RenderFormattedValue, RenderUnformattedValue, RenderFormula:
+		return true
+	}
+	return false
+}
+
+// GetSheetValues pulls the explicit tabular grid data from a range, rendered per
+// render (see RenderFormattedValue). An empty render means FORMATTED_VALUE.
+func (s *Service) GetSheetValues(ctx context.Context, spreadsheetId string, readRange string, render string) (*sheets.ValueRange, error) {
+	if render == "" {
+		render = RenderFormattedValue
+	}
+	resp, err := s.sheetsService.Spreadsheets.Values.Get(spreadsheetId, readRange).ValueRenderOption(render).Context(ctx).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve sheet values %s: %w", spreadsheetId, err)
+		return nil, apiErrorf(err, "unable to retrieve sheet values %s", spreadsheetId)
 	}
 	return resp, nil
 }
 
+// NamedRange is a named range of a spreadsheet in A1 notation.
+type NamedRange struct {
+	Name  string `json:"name"`
+	Range string `json:"range"`
+}
+
+// Chart describes a chart embedded in a spreadsheet.
+type Chart struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Type  string `json:"type,omitempty"`
+	Sheet string `json:"sheet"`
+}
+
+// ListNamedRanges extracts the named ranges of an already fetched spreadsheet.
+func ListNamedRanges(spreadsheet *sheets.Spreadsheet) []NamedRange {
+	if spreadsheet == nil {
+		return nil
+	}
+	titles := sheetTitlesByID(spreadsheet)
+	ranges := make([]NamedRange, 0, len(spreadsheet.NamedRanges))
+	for _, nr := range spreadsheet.NamedRanges {
+		ranges = append(ranges, NamedRange{Name: nr.Name, Range: gridRangeA1(nr.Range, titles)})
+	}
+	return ranges
+}
+
+// ListCharts extracts the charts embedded in the tabs of an already fetched spreadsheet.
+func ListCharts(spreadsheet *sheets.Spreadsheet) []Chart {
+	if spreadsheet == nil {
+		return nil
+	}
+	var charts []Chart
+	for _, sheet := range spreadsheet.Sheets {
+		var tab string
+		if sheet.Properties != nil {
+			tab = sheet.Properties.Title
+		}
+		for _, c := range sheet.Charts {
+			chart := Chart{ID: c.ChartId, Sheet: tab}
+			if c.Spec != nil {
+				chart.Title = c.Spec.Title
+				chart.Type = chartType(c.Spec)
+			}
+			charts = append(charts, chart)
+		}
+	}
+	return charts
+}
+
+// chartType names the kind of chart spec, using the basic chart type
+// (COLUMN, LINE, ...) where there is one.
+func chartType(spec *sheets.ChartSpec) string {
+	switch {
+	case spec.BasicChart != nil:
+		return spec.BasicChart.ChartType
+	case spec.PieChart != nil:
+		return "PIE"
+	case spec.HistogramChart != nil:
+		return "HISTOGRAM"
+	case spec.BubbleChart != nil:
+		return "BUBBLE"
+	case spec.CandlestickChart != nil:
+		return "CANDLESTICK"
+	case spec.OrgChart != nil:
+		return "ORG"
+	case spec.TreemapChart != nil:
+		return "TREEMAP"
+	case spec.WaterfallChart != nil:
+		return "WATERFALL"
+	case spec.ScorecardChart != nil:
+		return "SCORECARD"
+	default:
+		return ""
+	}
+}
+
+func sheetTitlesByID(spreadsheet *sheets.Spreadsheet) map[int64]string {
+	titles := make(map[int64]string, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil {
+			titles[sheet.Properties.SheetId] = sheet.Properties.Title
+		}
+	}
+	return titles
+}
+
+// gridRangeA1 renders a grid range in A1 notation. Grid indexes are zero-based
+// and half-open; an unset end index leaves that dimension unbounded.
+func gridRangeA1(gr *sheets.GridRange, titles map[int64]string) string {
+	if gr == nil {
+		return ""
+	}
+	tab := titles[gr.SheetId]
+	if strings.ContainsAny(tab, " '!") {
+		tab = "'" + strings.ReplaceAll(tab, "'", "''") + "'"
+	}
+
+	var cells string
+	switch {
+	case gr.EndColumnIndex > 0 && gr.EndRowIndex > 0:
+		cells = fmt.Sprintf("%s%d:%s%d", columnName(gr.StartColumnIndex), gr.StartRowIndex+1, columnName(gr.EndColumnIndex-1), gr.EndRowIndex)
+	case gr.EndColumnIndex > 0:
+		cells = columnName(gr.StartColumnIndex) + ":" + columnName(gr.EndColumnIndex-1)
+	case gr.EndRowIndex > 0:
+		cells = fmt.Sprintf("%d:%d", gr.StartRowIndex+1, gr.EndRowIndex)
+	default:
+		return tab
+	}
+	if tab == "" {
+		return cells
+	}
+	return tab + "!" + cells
+}
+
+// columnName converts a zero-based column index to its letters (0 is A, 26 is AA).
+func columnName(index int64) string {
+	var name []byte
+	for index >= 0 {
+		name = append([]byte{byte('A' + index%26)}, name...)
+		index = index/26 - 1
+	}
+	return string(name)
+}
+
 // AppendSheetRow pushes an array of values as a new row
 func (s *Service) AppendSheetRow(spreadsheetId string, writeRange string, values []interface{}) error {
 	valueRange := &sheets.ValueRange{
@@ -226,16 +548,19 @@ func (s *Service) AppendSheetRow(spreadsheetId string, writeRange string, values
 		Do()
 
 	if err != nil {
-		return fmt.Errorf("failed to append row to %s: %w", spreadsheetId, err)
+		return apiErrorf(err, "failed to append row to %s", spreadsheetId)
 	}
 	return nil
 }
 
 // DeleteSheet deletes a Google Sheet by its ID using the Drive API
 func (s *Service) DeleteSheet(spreadsheetId string) error {
-	err := s.driveService.Files.Delete(spreadsheetId).Do()
+	if err := s.checkExcluded(spreadsheetId); err != nil {
+		return err
+	}
+	err := s.deleteDriveFile(spreadsheetId)
 	if err != nil {
-		return fmt.Errorf("unable to delete sheet %s: %w", spreadsheetId, err)
+		return apiErrorf(err, "unable to delete sheet %s", spreadsheetId)
 	}
 	return nil
 }
@@ -244,31 +569,104 @@ func (s *Service) DeleteSheet(spreadsheetId string) error {
 func (s *Service) GetDoc(documentId string) (*docs.Document, error) {
 	doc, err := s.docsService.Documents.Get(documentId).Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve doc %s: %w", documentId, err)
+		return nil, apiErrorf(err, "unable to retrieve doc %s", documentId)
 	}
 	return doc, nil
 }
 
-// ExtractDocContent traverses the rich Google Doc structure and extracts a contiguous plain text string
+// ExtractDocContent traverses the rich Google Doc structure and extracts a contiguous plain text string.
+// Table rows become tab-separated lines, and horizontal rules and section breaks become "---".
 func ExtractDocContent(content []*docs.StructuralElement) string {
-	var text string
+	text, _ := ExtractDocContentLimit(content, 0)
+	return text
+}
+
+// ExtractDocContentLimit is ExtractDocContent capped at maxBytes of output. Extraction
+// stops as soon as the cap is reached, cutting on a rune boundary, and truncated
+// reports whether anything was dropped. A maxBytes of zero or less means no cap.
+func ExtractDocContentLimit(content []*docs.StructuralElement, maxBytes int) (text string, truncated bool) {
+	w := &docWriter{max: maxBytes}
+	w.writeContent(content)
+	return w.b.String(), w.truncated
+}
+
+// docWriter accumulates extracted document text up to an optional byte cap.
+type docWriter struct {
+	b         strings.Builder
+	max       int
+	truncated bool
+}
+
+// WriteString appends s, cutting it short and marking the writer truncated once
+// the cap would be exceeded.
+func (w *docWriter) WriteString(s string) {
+	if w.truncated {
+		return
+	}
+	if w.max > 0 && w.b.Len()+len(s) > w.max {
+		cut := w.max - w.b.Len()
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+		w.truncated = true
+	}
+	w.b.WriteString(s)
+}
+
+func (w *docWriter) writeContent(content []*docs.StructuralElement) {
 	for _, element := range content {
-		if element.Paragraph != nil {
+		if w.truncated {
+			return
+		}
+		switch {
+		case element.Paragraph != nil:
 			for _, element := range element.Paragraph.Elements {
-				if element.TextRun != nil {
-					text += element.TextRun.Content
+				switch {
+				case element.TextRun != nil:
+					w.WriteString(element.TextRun.Content)
+				case element.HorizontalRule != nil:
+					w.WriteString("---")
 				}
 			}
+		case element.Table != nil:
+			for _, row := range element.Table.TableRows {
+				if row == nil || w.truncated {
+					continue
+				}
+				for i, cell := range row.TableCells {
+					if i > 0 {
+						w.WriteString("\t")
+					}
+					if cell != nil {
+						w.WriteString(docCellText(cell))
+					}
+				}
+				w.WriteString("\n")
+			}
+		case element.SectionBreak != nil:
+			// Every body opens with a section break; only mark the ones between content.
+			if w.b.Len() > 0 {
+				w.WriteString("---\n")
+			}
 		}
 	}
-	return text
+}
+
+// docCellText flattens a table cell onto a single line.
+func docCellText(cell *docs.TableCell) string {
+	text := strings.TrimSpace(ExtractDocContent(cell.Content))
+	return strings.Join(strings.Fields(strings.ReplaceAll(text, "\t", " ")), " ")
 }
 
 // DeleteDoc deletes a Google Doc by its ID using the Drive API
 func (s *Service) DeleteDoc(documentId string) error {
-	err := s.driveService.Files.Delete(documentId).Do()
+	if err := s.checkExcluded(documentId); err != nil {
+		return err
+	}
+	err := s.deleteDriveFile(documentId)
 	if err != nil {
-		return fmt.Errorf("unable to delete doc %s: %w", documentId, err)
+		return apiErrorf(err, "unable to delete doc %s", documentId)
 	}
 	return nil
 }
@@ -277,7 +675,7 @@ func (s *Service) DeleteDoc(documentId string) error {
 func (s *Service) GetGmailThread(threadId string) (*gmail.Thread, error) {
 	thread, err := s.gmailService.Users.Threads.Get("me", threadId).Format("full").Do()
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve gmail thread %s: %w", threadId, err)
+		return nil, apiErrorf(err, "unable to retrieve gmail thread %s", threadId)
 	}
 	return thread, nil
 }
@@ -286,7 +684,7 @@ func (s *Service) GetGmailThread(threadId string) (*gmail.Thread, error) {
 func (s *Service) TrashGmailThread(threadId string) error {
 	_, err := s.gmailService.Users.Threads.Trash("me", threadId).Do()
 	if err != nil {
-		return fmt.Errorf("failed to trash gmail thread %s: %w", threadId, err)
+		return apiErrorf(err, "failed to trash gmail thread %s", threadId)
 	}
 	return nil
 }
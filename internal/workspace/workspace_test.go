@@ -9,9 +9,12 @@ registry item consolidation, and Google API service wrapping logic.
 package workspace
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	admin "google.golang.org/api/admin/directory/v1"
@@ -24,6 +27,39 @@ import (
 	sheets "google.golang.org/api/sheets/v4"
 )
 
+// testAPI selects the Google APIs newTestService wires to its stub server.
+type testAPI int
+
+const (
+	testKeep testAPI = 1 << iota
+	testDrive
+)
+
+// newTestService builds a Service whose selected APIs talk to handler; the
+// others are left nil.
+func newTestService(t *testing.T, apis testAPI, handler http.HandlerFunc) *Service {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	var keepSvc *keep.Service
+	var driveSvc *drive.Service
+	var err error
+	if apis&testKeep != 0 {
+		if keepSvc, err = keep.NewService(ctx, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if apis&testDrive != 0 {
+		if driveSvc, err = drive.NewService(ctx, opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+}
+
 func TestNewService(t *testing.T) {
 	adminSvc := &admin.Service{}
 	keepSvc := &keep.Service{}
@@ -34,7 +70,7 @@ func TestNewService(t *testing.T) {
 	chatUserSvc := &chat.Service{}
 	chatBotSvc := &chat.Service{}
 
-	ws := NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, gmailSvc, chatUserSvc, chatBotSvc)
+	ws := NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, gmailSvc, chatUserSvc, chatBotSvc, nil)
 
 	if ws.adminService != adminSvc {
 		t.Error("Admin service not correctly assigned")
@@ -86,8 +122,8 @@ func TestListRegistryItems(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil)
-	items, err := ws.ListRegistryItems()
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+	items, err := ws.ListRegistryItems(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,3 +175,266 @@ func TestExtractDocContent(t *testing.T) {
 		t.Errorf("expected '%s', got '%s'", expected, result)
 	}
 }
+
+func TestListRegistryItemsTimestamps(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": [{"name": "notes/1", "title": "Note", "createTime": "2026-01-01T10:00:00.123456Z", "updateTime": "2026-01-02T11:00:00Z"}]}`))
+		case r.URL.Path == "/files":
+			if fields := r.URL.Query().Get("fields"); !strings.Contains(fields, "modifiedTime") || !strings.Contains(fields, "createdTime") {
+				t.Errorf("expected drive fields to request timestamps, got %q", fields)
+			}
+			if strings.Contains(r.URL.Query().Get("q"), "document") {
+				w.Write([]byte(`{"files": [{"id": "doc-1", "name": "Doc", "createdTime": "2026-02-01T00:00:00Z", "modifiedTime": "2026-02-03T04:05:06.789Z"}]}`))
+				return
+			}
+			if strings.Contains(r.URL.Query().Get("q"), "form") {
+				w.Write([]byte(`{"files": []}`))
+				return
+			}
+			w.Write([]byte(`{"files": [{"id": "sheet-1", "name": "Sheet", "createdTime": "2026-03-01T00:00:00Z", "modifiedTime": "2026-03-02T00:00:00Z"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/threads"):
+			w.Write([]byte(`{"threads": [{"id": "t1", "snippet": "hi"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/threads/t1"):
+			w.Write([]byte(`{"id": "t1", "messages": [
+				{"internalDate": "1767225600000", "payload": {"headers": [{"name": "Subject", "value": "Hello"}]}},
+				{"internalDate": "1767312000000", "payload": {"headers": []}}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+	gmailSvc, _ := gmail.NewService(ctx, opts...)
+
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, gmailSvc, nil, nil, nil)
+	items, err := ws.ListRegistryItems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]string{
+		"notes/1": {"2026-01-01T10:00:00Z", "2026-01-02T11:00:00Z"},
+		"doc-1":   {"2026-02-01T00:00:00Z", "2026-02-03T04:05:06Z"},
+		"sheet-1": {"2026-03-01T00:00:00Z", "2026-03-02T00:00:00Z"},
+		"t1":      {"2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(items))
+	}
+	for _, item := range items {
+		w, ok := want[item.ID]
+		if !ok {
+			t.Errorf("unexpected item %s", item.ID)
+			continue
+		}
+		if item.CreatedTime != w[0] || item.ModifiedTime != w[1] {
+			t.Errorf("%s (%s): expected created=%s modified=%s, got created=%s modified=%s",
+				item.ID, item.Type, w[0], w[1], item.CreatedTime, item.ModifiedTime)
+		}
+	}
+}
+
+func TestListRegistryItemsDropsDuplicates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/notes":
+			w.Write([]byte(`{"notes": []}`))
+		case strings.Contains(r.URL.Query().Get("q"), "document"):
+			w.Write([]byte(`{"files": [
+				{"id": "doc-1", "name": "Shared Twice", "parents": ["a"]},
+				{"id": "doc-1", "name": "Shared Twice", "parents": ["b"]}
+			]}`))
+		default:
+			w.Write([]byte(`{"files": []}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	opts := []option.ClientOption{option.WithEndpoint(ts.URL), option.WithoutAuthentication()}
+	keepSvc, _ := keep.NewService(ctx, opts...)
+	driveSvc, _ := drive.NewService(ctx, opts...)
+
+	ws := NewService(nil, keepSvc, nil, nil, driveSvc, nil, nil, nil, nil)
+	var logs bytes.Buffer
+	ws.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	items, err := ws.ListRegistryItems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "doc-1" {
+		t.Fatalf("expected a single doc-1, got %+v", items)
+	}
+	if !strings.Contains(logs.String(), "dropping duplicate registry item") {
+		t.Errorf("expected the duplicate to be logged to the service logger, got %q", logs.String())
+	}
+}
+
+func TestExtractDocContentTablesAndBreaks(t *testing.T) {
+	para := func(text string) *docs.StructuralElement {
+		return &docs.StructuralElement{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: text}}}}}
+	}
+	cell := func(text string) *docs.TableCell {
+		return &docs.TableCell{Content: []*docs.StructuralElement{para(text)}}
+	}
+	content := []*docs.StructuralElement{
+		{SectionBreak: &docs.SectionBreak{}},
+		para("Intro\n"),
+		{Table: &docs.Table{TableRows: []*docs.TableRow{
+			{TableCells: []*docs.TableCell{cell("Name\n"), cell("Owner\n")}},
+			{TableCells: []*docs.TableCell{cell("Axis\n"), cell("Ada\n")}},
+		}}},
+		{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{HorizontalRule: &docs.HorizontalRule{}}, {TextRun: &docs.TextRun{Content: "\n"}}}}},
+		{SectionBreak: &docs.SectionBreak{}},
+		para("Appendix\n"),
+	}
+
+	want := "Intro\nName\tOwner\nAxis\tAda\n---\n---\nAppendix\n"
+	if got := ExtractDocContent(content); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestExtractDocContentLimit(t *testing.T) {
+	para := func(text string) *docs.StructuralElement {
+		return &docs.StructuralElement{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{{TextRun: &docs.TextRun{Content: text}}}}}
+	}
+	content := []*docs.StructuralElement{para("Hello\n"), para("naïve world\n"), para("never read\n")}
+
+	tests := []struct {
+		max       int
+		want      string
+		truncated bool
+	}{
+		{0, "Hello\nnaïve world\nnever read\n", false},
+		{30, "Hello\nnaïve world\nnever read\n", false},
+		{6, "Hello\n", true},
+		{9, "Hello\nna", true}, // the two-byte ï would straddle the cap
+		{10, "Hello\nnaï", true},
+	}
+	for _, tc := range tests {
+		got, truncated := ExtractDocContentLimit(content, tc.max)
+		if got != tc.want || truncated != tc.truncated {
+			t.Errorf("max %d: expected (%q, %v), got (%q, %v)", tc.max, tc.want, tc.truncated, got, truncated)
+		}
+	}
+}
+
+// concatDocContent is the naive += extraction, kept as a benchmark baseline.
+func concatDocContent(content []*docs.StructuralElement) string {
+	text := ""
+	for _, element := range content {
+		if element.Paragraph == nil {
+			continue
+		}
+		for _, element := range element.Paragraph.Elements {
+			if element.TextRun != nil {
+				text += element.TextRun.Content
+			}
+		}
+	}
+	return text
+}
+
+func BenchmarkExtractDocContent(b *testing.B) {
+	content := make([]*docs.StructuralElement, 5000)
+	for i := range content {
+		content[i] = &docs.StructuralElement{Paragraph: &docs.Paragraph{Elements: []*docs.ParagraphElement{
+			{TextRun: &docs.TextRun{Content: "The quick brown fox jumps over the lazy dog.\n"}},
+		}}}
+	}
+
+	b.Run("builder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ExtractDocContent(content)
+		}
+	})
+	b.Run("concat", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			concatDocContent(content)
+		}
+	})
+}
+
+func TestGetSheetValuesRenderOption(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get("valueRenderOption"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"range": "A1:B1", "values": [["=SUM(1,2)"]]}`))
+	}))
+	t.Cleanup(ts.Close)
+	sheetsSvc, err := sheets.NewService(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := NewService(nil, nil, nil, sheetsSvc, nil, nil, nil, nil, nil)
+
+	if _, err := ws.GetSheetValues(context.Background(), "sheet-1", "A1:B1", RenderFormula); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.GetSheetValues(context.Background(), "sheet-1", "A1:B1", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{RenderFormula, RenderFormattedValue}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected valueRenderOption %v, got %v", want, got)
+	}
+}
+
+func TestListNamedRangesAndCharts(t *testing.T) {
+	spreadsheet := &sheets.Spreadsheet{
+		Sheets: []*sheets.Sheet{
+			{
+				Properties: &sheets.SheetProperties{SheetId: 0, Title: "Data"},
+			},
+			{
+				Properties: &sheets.SheetProperties{SheetId: 7, Title: "Q1 Report"},
+				Charts: []*sheets.EmbeddedChart{
+					{ChartId: 42, Spec: &sheets.ChartSpec{Title: "Revenue", BasicChart: &sheets.BasicChartSpec{ChartType: "COLUMN"}}},
+					{ChartId: 43, Spec: &sheets.ChartSpec{Title: "Share", PieChart: &sheets.PieChartSpec{}}},
+				},
+			},
+		},
+		NamedRanges: []*sheets.NamedRange{
+			{Name: "Totals", Range: &sheets.GridRange{SheetId: 0, StartRowIndex: 1, EndRowIndex: 10, StartColumnIndex: 0, EndColumnIndex: 28}},
+			{Name: "Regions", Range: &sheets.GridRange{SheetId: 7, StartColumnIndex: 2, EndColumnIndex: 3}},
+			{Name: "Everything", Range: &sheets.GridRange{SheetId: 0}},
+		},
+	}
+
+	ranges := ListNamedRanges(spreadsheet)
+	want := []NamedRange{
+		{Name: "Totals", Range: "Data!A2:AB10"},
+		{Name: "Regions", Range: "'Q1 Report'!C:C"},
+		{Name: "Everything", Range: "Data"},
+	}
+	if len(ranges) != len(want) {
+		t.Fatalf("expected %d named ranges, got %+v", len(want), ranges)
+	}
+	for i := range want {
+		if ranges[i] != want[i] {
+			t.Errorf("range %d: expected %+v, got %+v", i, want[i], ranges[i])
+		}
+	}
+
+	charts := ListCharts(spreadsheet)
+	wantCharts := []Chart{
+		{ID: 42, Title: "Revenue", Type: "COLUMN", Sheet: "Q1 Report"},
+		{ID: 43, Title: "Share", Type: "PIE", Sheet: "Q1 Report"},
+	}
+	if len(charts) != len(wantCharts) || charts[0] != wantCharts[0] || charts[1] != wantCharts[1] {
+		t.Errorf("expected %+v, got %+v", wantCharts, charts)
+	}
+}
@@ -276,7 +276,7 @@ const App = () => {
                             isDoc={visibleRegistry[selectedIndex]?.type === 'doc'}
                             isSheet={visibleRegistry[selectedIndex]?.type === 'sheet'}
                             isGmail={visibleRegistry[selectedIndex]?.type === 'gmail'}
-                            detailContent={visibleRegistry[selectedIndex]?.type === 'keep' ? formatNoteContent.fromNote(detailItem) : (visibleRegistry[selectedIndex]?.type === 'doc' || visibleRegistry[selectedIndex]?.type === 'gmail') ? detailItem?.content : null}
+                            detailContent={visibleRegistry[selectedIndex]?.type === 'keep' ? formatNoteContent.fromNote(detailItem) : (visibleRegistry[selectedIndex]?.type === 'doc' || visibleRegistry[selectedIndex]?.type === 'form' || visibleRegistry[selectedIndex]?.type === 'gmail') ? detailItem?.content : null}
                             sheetValues={visibleRegistry[selectedIndex]?.type === 'sheet' ? detailItem?.values : null}
                             detailItem={detailItem}
                             detailLoading={detailLoading}
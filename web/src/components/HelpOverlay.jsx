@@ -40,14 +40,32 @@ const HelpOverlay = ({ isOpen, onClose }) => {
                             </thead>
                             <tbody>
                                 <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry</td><td>GET</td><td>Unified registry stream state</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry?refresh=1</td><td>GET</td><td>Manual fetch (R key)</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/{'{'}notes|docs|sheets|gmail{'}'}/detail?id=X</td><td>GET</td><td>Detail payload for selected item</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-red-400">/api/{'{'}notes|docs|sheets|gmail{'}'}/delete?id=X</td><td>DELETE</td><td>Purge selected item</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-yellow-400">/api/status?id=X&amp;status=Y</td><td>POST</td><td>Update Keep status (cycle keys)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry?refresh=1</td><td>GET</td><td>Manual fetch (R key); at most one per 2s by default</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry?fields=id,title</td><td>GET</td><td>Registry trimmed to the named fields</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry/export.ndjson</td><td>GET</td><td>Registry as newline-delimited JSON</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-green-400">/api/registry/export.csv</td><td>GET</td><td>Registry as CSV (?type= and ?q= filter)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/{'{'}notes|docs|sheets|forms|gmail{'}'}/detail?id=X</td><td>GET</td><td>Detail payload for selected item</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/docs/detail?id=X&amp;maxBytes=N</td><td>GET</td><td>Doc text capped at N bytes; sets truncated</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-red-400">/api/{'{'}notes|docs|sheets|forms|gmail{'}'}/delete?id=X</td><td>DELETE</td><td>Purge selected item (shared notes need &amp;force=true)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-red-400">/api/notes/batch-delete</td><td>POST</td><td>Delete notes by JSON id array (MANUAL)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-red-400">/api/registry/{'{'}protect|unprotect{'}'}?id=X</td><td>POST</td><td>Pin or release an item's delete protection</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/notes/content-batch</td><td>POST</td><td>Content + status for a JSON id array</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/notes/search?q=X</td><td>GET</td><td>Notes matching every term (&amp;includeBody=true, limit/offset)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-yellow-400">/api/status?id=X&amp;status=Y</td><td>POST</td><td>Update Keep status (cycle keys), optional &amp;annotation=</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-yellow-400">/api/status/{'{'}advance|regress{'}'}?id=X</td><td>POST</td><td>Step an item to the next or previous lifecycle status</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-yellow-400">/api/status/transition</td><td>POST</td><td>Move every item from one status to another</td></tr>
                                 <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/mode</td><td>GET</td><td>Current Auto/Manual state</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/mode?set=X</td><td>GET</td><td>Switch to AUTO or MANUAL</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/mode?set=X</td><td>POST</td><td>Switch to AUTO or MANUAL</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/mode/{'{'}pause|resume{'}'}</td><td>POST</td><td>Freeze or resume the AUTO countdown</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/mode/countdown</td><td>GET</td><td>Seconds until the next AUTO refresh</td></tr>
                                 <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/user</td><td>GET</td><td>Active user profile</td></tr>
-                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-blue-400">/api/events</td><td>SSE</td><td>Live registry + tick/status events</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/health/detail</td><td>GET</td><td>Per-subsystem health report</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/sheets/values/page?id=X&amp;page=N&amp;size=M</td><td>GET</td><td>Paged sheet rows; total in X-Total-Count</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-cyan-400">/api/sheets/meta?id=X</td><td>GET</td><td>Sheet tabs, named ranges and charts</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-purple-400">/api/openapi.json</td><td>GET</td><td>OpenAPI 3 description of the API</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-blue-400">/api/events</td><td>SSE</td><td>Live registry + tick/status events (?events= to filter)</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-blue-400">/api/events/resync?token=X</td><td>POST</td><td>Resend the registry snapshot to a stream</td></tr>
+                                <tr className="border-t border-gray-800 hover:bg-white/5"><td className="py-1 text-blue-400">/api/events/clients</td><td>GET</td><td>Connected stream clients (diagnostics)</td></tr>
                             </tbody>
                         </table>
                     </section>
@@ -9,6 +9,7 @@ import {
     getDetail as apiGetDetail,
     deleteResource,
     setStatus as apiSetStatus,
+    resyncEvents,
     getUser,
     normalizeRegistry,
 } from '../utils/apiClient';
@@ -96,8 +97,18 @@ export function useRegistry({ addLog, onRegistryChange } = {}) {
 
     useEffect(() => {
         const es = new EventSource('/api/events');
+        let resyncTimer = null;
         es.onopen = () => { setConnected(true); addLog?.('success', 'Uplink established (SSE).'); };
+        es.addEventListener('connected', (e) => {
+            // Ask again if the initial snapshot never arrives.
+            clearTimeout(resyncTimer);
+            try {
+                const { token } = JSON.parse(e.data);
+                resyncTimer = setTimeout(() => { resyncEvents(token).catch(() => {}); }, 5000);
+            } catch (err) { console.error('Connected event parse error', err); }
+        });
         es.onmessage = (e) => {
+            clearTimeout(resyncTimer);
             try {
                 const data = JSON.parse(e.data);
                 const normalized = normalizeRegistry(data);
@@ -121,13 +132,14 @@ export function useRegistry({ addLog, onRegistryChange } = {}) {
                 const data = JSON.parse(e.data);
                 if (data.status && data.title) {
                     const logType = data.status;
-                    addLog?.(logType, `Status → ${data.status}: ${data.title}`);
+                    const transition = data.previous ? `${data.previous} → ${data.status}` : `→ ${data.status}`;
+                    addLog?.(logType, `Status ${transition}: ${data.title}`);
                 }
             } catch (err) { console.error('Status event parse error', err); }
         });
 
         es.onerror = () => setConnected(false);
-        return () => { es.close(); setConnected(false); };
+        return () => { clearTimeout(resyncTimer); es.close(); setConnected(false); };
     }, [addLog, onRegistryChange]);
 
     return {
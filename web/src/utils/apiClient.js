@@ -16,6 +16,7 @@ export const normalizeRegistryItem = (item) => {
         type: item.type || 'keep',
         title,
         status,
+        annotation: item.annotation || '',
         snippet,
         raw: item,
     };
@@ -33,7 +34,11 @@ export async function getMode() {
 }
 
 export async function setMode(mode) {
-    return fetchJson(`/api/mode?set=${mode}`, { timeout: DEFAULT_TIMEOUT, retry: DEFAULT_RETRY });
+    return fetchJson(`/api/mode?set=${mode}`, { method: 'POST', timeout: DEFAULT_TIMEOUT, retry: DEFAULT_RETRY });
+}
+
+export async function resyncEvents(token) {
+    return fetch(`/api/events/resync?token=${encodeURIComponent(token)}`, { method: 'POST' });
 }
 
 export async function getUser() {
@@ -59,6 +64,9 @@ export async function getDetail(item) {
         case 'sheet':
             url = `/api/sheets/detail?id=${encodeURIComponent(item.id)}`;
             break;
+        case 'form':
+            url = `/api/forms/detail?id=${encodeURIComponent(item.id)}`;
+            break;
         case 'gmail':
             url = `/api/gmail/detail?id=${encodeURIComponent(item.id)}`;
             break;
@@ -68,6 +76,16 @@ export async function getDetail(item) {
     return fetchJson(url, { timeout: DEFAULT_TIMEOUT, retry: DEFAULT_RETRY });
 }
 
+export async function searchNotes(query, includeBody = false) {
+    const params = new URLSearchParams({ q: query });
+    if (includeBody) params.set('includeBody', 'true');
+    return fetchJson(`/api/notes/search?${params}`, { timeout: DEFAULT_TIMEOUT, retry: DEFAULT_RETRY });
+}
+
+export async function getSheetMeta(id) {
+    return fetchJson(`/api/sheets/meta?id=${encodeURIComponent(id)}`, { timeout: DEFAULT_TIMEOUT, retry: DEFAULT_RETRY });
+}
+
 export async function deleteResource(item) {
     if (!item || !item.id) return;
     let url = '';
@@ -81,6 +99,9 @@ export async function deleteResource(item) {
         case 'sheet':
             url = `/api/sheets/delete?id=${encodeURIComponent(item.id)}`;
             break;
+        case 'form':
+            url = `/api/forms/delete?id=${encodeURIComponent(item.id)}`;
+            break;
         case 'gmail':
             url = `/api/gmail/delete?id=${encodeURIComponent(item.id)}`;
             break;
@@ -91,7 +112,35 @@ export async function deleteResource(item) {
     if (!res.ok) throw new Error('Purge request failed');
 }
 
-export async function setStatus(item, status) {
+export async function setStatus(item, status, annotation) {
+    if (!item || !item.id) return;
+    let url = `/api/status?id=${encodeURIComponent(item.id)}&status=${status}`;
+    if (annotation !== undefined) url += `&annotation=${encodeURIComponent(annotation)}`;
+    return fetch(url, { method: 'POST' });
+}
+
+export async function stepStatus(item, forward = true) {
+    if (!item || !item.id) return;
+    const action = forward ? 'advance' : 'regress';
+    const res = await fetch(`/api/status/${action}?id=${encodeURIComponent(item.id)}`, { method: 'POST' });
+    if (!res.ok) throw new Error(`Failed to ${action} status`);
+    return res.json();
+}
+
+export async function transitionStatuses(from, to) {
+    const res = await fetch('/api/status/transition', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ from, to }),
+    });
+    if (!res.ok) throw new Error('Status transition failed');
+    return res.json();
+}
+
+export async function setProtected(item, protect) {
     if (!item || !item.id) return;
-    return fetch(`/api/status?id=${encodeURIComponent(item.id)}&status=${status}`, { method: 'POST' });
+    const action = protect ? 'protect' : 'unprotect';
+    const res = await fetch(`/api/registry/${action}?id=${encodeURIComponent(item.id)}`, { method: 'POST' });
+    if (!res.ok) throw new Error(`Failed to ${action} item`);
+    return res.json();
 }